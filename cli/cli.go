@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/ahobsonsayers/t4g-feed/config"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/lo"
+)
+
+const programName = "t4g-feed"
+
+// command is a subcommand of the program
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{name: "serve", description: "Run the feed server (the default if no command is given)", run: serve},
+	{name: "events", description: "Print the events of a location", run: events},
+	{name: "feed", description: "Print a feed of the events of a location", run: feed},
+	{name: "export", description: "Write a feed to a file, keeping its history in the data directory", run: export},
+	{name: "site", description: "Generate a static site of the feeds of locations", run: generateSite},
+}
+
+// Run runs the command chosen by the arguments (without the program name), writing its output to stdout.
+// If the arguments do not start with a command, the server is run so existing deployments keep working.
+func Run(ctx context.Context, args []string, stdout io.Writer) error {
+	commandName := "serve"
+	if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+		commandName, args = args[0], args[1:]
+	}
+
+	if commandName == "help" {
+		usage(stdout)
+		return nil
+	}
+
+	command, ok := lo.Find(commands, func(command command) bool { return command.name == commandName })
+	if !ok {
+		usage(os.Stderr)
+		return fmt.Errorf("unknown command %q", commandName)
+	}
+
+	return command.run(ctx, args, stdout)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", programName)
+	for _, command := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", command.name, command.description)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' to see the flags of a command.\n", programName)
+}
+
+// newFlagSet creates the flag set of a command
+func newFlagSet(commandName string) *flag.FlagSet {
+	return flag.NewFlagSet(fmt.Sprintf("%s %s", programName, commandName), flag.ContinueOnError)
+}
+
+// newClient creates a client from the configuration, storing feeds in the data directory if it is set
+func newClient(cfg *config.Config) (*t4g.Client, error) {
+	// Metrics are registered with the default registerer, which the server exports
+	clientOpts := append(cfg.ClientOptions(), t4g.WithRegisterer(prometheus.DefaultRegisterer))
+	if cfg.DataDir != "" {
+		feedStore, err := t4g.NewDirFeedStore(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create feed store: %w", err)
+		}
+		clientOpts = append(clientOpts, t4g.WithFeedStore(feedStore))
+	}
+
+	client, err := t4g.NewClient(clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return client, nil
+}
+
+// signalContext returns a context that is cancelled when an interrupt or terminate signal is received
+func signalContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+}
+
+// searchFlags are the flags of commands that get the events of locations
+type searchFlags struct {
+	radius int
+	portal string
+	pages  int
+}
+
+func (f *searchFlags) bind(flagSet *flag.FlagSet) {
+	flagSet.IntVar(&f.radius, "radius", 0, "Search radius in miles. If not set, 30 miles.")
+	flagSet.StringVar(&f.portal, "portal", "", "Portal to get events from. If not set, the default portal.")
+	flagSet.IntVar(&f.pages, "pages", 0, "Number of event pages to get. If not set, the configured -event-pages.")
+}
+
+// loadConfig parses the arguments of a command and loads the configuration.
+// The number of pages of the search flags is applied to the configuration.
+func (f *searchFlags) loadConfig(flagSet *flag.FlagSet, args []string) (*config.Config, error) {
+	cfg, err := config.LoadFlags(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if flagSet.NArg() != 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flagSet.Args(), " "))
+	}
+	if f.radius < 0 {
+		return nil, errors.New("radius must not be negative")
+	}
+
+	if f.pages != 0 {
+		cfg.EventPages = f.pages
+		err = cfg.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid pages: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// lookupPortal looks up the chosen portal. If no portal is chosen, nil is returned so the default portal is used.
+func (f *searchFlags) lookupPortal(client *t4g.Client) (*t4g.Portal, error) {
+	if f.portal == "" {
+		return nil, nil
+	}
+
+	portal, err := client.LookupPortal(f.portal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &portal, nil
+}
+
+// radiusOrNil returns the chosen radius, or nil if no radius is chosen
+func (f *searchFlags) radiusOrNil() *int {
+	return lo.EmptyableToPtr(f.radius)
+}
+
+// bindLocation binds the flag of the location to get events near
+func bindLocation(flagSet *flag.FlagSet, location *string) {
+	flagSet.StringVar(location, "location", "", "Location (or postcode) to get events near. If not set, all events.")
+}
+
+// filterFlags are the flags of commands that filter the events of a feed
+type filterFlags struct {
+	categories        stringsFlag
+	excludeCategories stringsFlag
+	query             string
+	titleRegex        string
+}
+
+func (f *filterFlags) bind(flagSet *flag.FlagSet) {
+	flagSet.Var(&f.categories, "category", "Only include events in this category. Can be repeated.")
+	flagSet.Var(&f.excludeCategories, "exclude-category", "Exclude events in this category. Can be repeated.")
+	flagSet.StringVar(&f.query, "q", "", "Only include events whose title or location contains this text")
+	flagSet.StringVar(&f.titleRegex, "title-regex", "", "Only include events whose title matches this regex")
+}
+
+func (f *filterFlags) filter() (t4g.EventFilter, error) {
+	var titleRegex *regexp.Regexp
+	if f.titleRegex != "" {
+		var err error
+		titleRegex, err = regexp.Compile(f.titleRegex)
+		if err != nil {
+			return t4g.EventFilter{}, fmt.Errorf("invalid title regex: %w", err)
+		}
+	}
+
+	return t4g.EventFilter{
+		Categories:        f.categories,
+		ExcludeCategories: f.excludeCategories,
+		Query:             lo.EmptyableToPtr(f.query),
+		TitleRegex:        titleRegex,
+	}, nil
+}
+
+// stringsFlag is a flag that can be repeated, collecting each value
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// checkFormat checks a format is one of the formats a command can output
+func checkFormat(format string, formats ...string) error {
+	if !lo.Contains(formats, format) {
+		return fmt.Errorf("unknown format %q: must be one of %s", format, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+// logPartialError logs that some event pages could not be fetched
+func logPartialError(ctx context.Context, partialErr *t4g.PartialError) {
+	slog.WarnContext(
+		ctx, "Some event pages could not be fetched, continuing without them",
+		"failedPages", partialErr.FailedPages(), "error", partialErr,
+	)
+}
@@ -0,0 +1,126 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ahobsonsayers/t4g-feed/cli"
+	"github.com/ahobsonsayers/t4g-feed/server"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/stretchr/testify/require"
+)
+
+// run runs a command against a test server, returning its output
+func run(t *testing.T, upstream *t4gtest.Server, args ...string) (string, error) {
+	t.Helper()
+
+	args = append(args, "-portals", "nhs="+upstream.URL, "-upstream-attempts", "1")
+
+	var stdout bytes.Buffer
+	err := cli.Run(context.Background(), args, &stdout)
+	return stdout.String(), err
+}
+
+func TestEvents(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+
+	output, err := run(t, upstream, "events", "--location", "leeds", "--pages", "2", "--format", "json")
+	require.NoError(t, err)
+	require.Equal(t, 1, upstream.PageRequests("leeds", 2))
+
+	var events []server.Event
+	err = json.Unmarshal([]byte(output), &events)
+	require.NoError(t, err)
+	require.Len(t, events, t4gtest.Page1Events+t4gtest.Page2Events)
+	require.Equal(t, t4gtest.FirstEventId, events[0].Id)
+	require.Equal(t, "Hamilton", events[0].Title)
+
+	output, err = run(t, upstream, "events", "--location", "leeds", "--pages", "1")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, t4gtest.Page1Events+1)
+	require.True(t, strings.HasPrefix(lines[0], "ID"))
+	require.True(t, strings.HasPrefix(lines[1], strconv.Itoa(t4gtest.FirstEventId)))
+}
+
+func TestFeed(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+
+	output, err := run(t, upstream, "feed", "--location", "london", "--pages", "1", "--format", "atom")
+	require.NoError(t, err)
+	require.Contains(t, output, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	require.Contains(t, output, "<title>T4G Feed: London</title>")
+	require.Contains(t, output, "<title>Hamilton</title>")
+
+	output, err = run(t, upstream, "feed", "--location", "london", "--pages", "1", "--category", "music")
+	require.NoError(t, err)
+	require.Contains(t, output, "<rss")
+	require.NotContains(t, output, "<title>Hamilton</title>")
+}
+
+func TestExport(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	dataDir := t.TempDir()
+	outputPath := filepath.Join(t.TempDir(), "feed.json")
+
+	args := []string{"export", "--location", "london", "--pages", "1", "--format", "json", "--data-dir", dataDir}
+	_, err := run(t, upstream, append(args, "--output", outputPath)...)
+	require.NoError(t, err)
+
+	firstExport, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Contains(t, string(firstExport), `"title": "Hamilton"`)
+
+	// Exporting again should keep the history of the feed, so the feed is unchanged
+	output, err := run(t, upstream, args...)
+	require.NoError(t, err)
+	require.Equal(t, string(firstExport), output)
+	require.Equal(t, 2, upstream.PageRequests("london", 1))
+
+	// Exports need somewhere to keep the history of the feed
+	_, err = run(t, upstream, "export", "--location", "london")
+	require.ErrorContains(t, err, "data dir must be set")
+}
+
+func TestRunInvalid(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+
+	_, err := run(t, upstream, "unknown")
+	require.ErrorContains(t, err, `unknown command "unknown"`)
+
+	_, err = run(t, upstream, "feed", "--format", "xml")
+	require.ErrorContains(t, err, `unknown format "xml"`)
+
+	_, err = run(t, upstream, "events", "--pages", "100")
+	require.ErrorContains(t, err, "event pages must be between")
+}
+
+func TestSite(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	outputDir := t.TempDir()
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	args := []string{"site", "--location", "london", "--location", "leeds", "--pages", "1", "--output-dir", outputDir}
+	_, err := run(t, upstream, append(args, "--state-file", stateFile)...)
+	require.NoError(t, err)
+
+	for _, file := range []string{"index.html", "feeds.opml", "london.rss", "leeds.ics"} {
+		require.FileExists(t, filepath.Join(outputDir, file))
+	}
+	require.FileExists(t, stateFile)
+
+	// The state file must be set, and not be published with the site
+	_, err = run(t, upstream, args...)
+	require.ErrorContains(t, err, "state file must be set")
+	_, err = run(t, upstream, append(args, "--state-file", filepath.Join(outputDir, "state.json"))...)
+	require.ErrorContains(t, err, "state file must be outside the output dir")
+
+	_, err = run(t, upstream, "site", "--output-dir", outputDir, "--state-file", stateFile)
+	require.ErrorContains(t, err, "at least one location must be set")
+}
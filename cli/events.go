@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ahobsonsayers/t4g-feed/server"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/samber/lo"
+)
+
+// events prints the events of a location, newest first
+func events(ctx context.Context, args []string, stdout io.Writer) error {
+	flagSet := newFlagSet("events")
+	var location string
+	bindLocation(flagSet, &location)
+	var search searchFlags
+	search.bind(flagSet)
+	format := flagSet.String("format", "text", "Format to print events in, one of text or json")
+
+	cfg, err := search.loadConfig(flagSet, args)
+	if err != nil {
+		return err
+	}
+	err = checkFormat(*format, "text", "json")
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	portal, err := search.lookupPortal(client)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext(ctx)
+	defer stop()
+
+	events, err := client.Events(ctx, portal, lo.EmptyableToPtr(location), search.radiusOrNil(), &cfg.EventPages)
+	var partialErr *t4g.PartialError
+	if errors.As(err, &partialErr) {
+		logPartialError(ctx, partialErr)
+	} else if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+
+	if *format == "json" {
+		return writeEventsJSON(stdout, events)
+	}
+	return writeEventsText(stdout, events)
+}
+
+// writeEventsJSON writes events as a json array, using the same representation as the api
+func writeEventsJSON(w io.Writer, events []t4g.Event) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(lo.Map(events, func(event t4g.Event, _ int) server.Event { return server.NewEvent(event) }))
+}
+
+// writeEventsText writes events as a table, with an event on each line
+func writeEventsText(w io.Writer, events []t4g.Event) error {
+	tableWriter := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tableWriter, "ID\tTITLE\tDATE\tLOCATION\tCATEGORY\tLINK")
+	for _, event := range events {
+		fmt.Fprintf(
+			tableWriter, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			event.Id, event.Title, event.Date, event.Location, event.Category, event.Link,
+		)
+	}
+	return tableWriter.Flush()
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/ahobsonsayers/t4g-feed/config"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/utils"
+	"github.com/samber/lo"
+)
+
+// feedCommand is a command that creates a feed of the events of a location
+type feedCommand struct {
+	location string
+	search   searchFlags
+	filter   filterFlags
+	format   string
+}
+
+func (c *feedCommand) bind(flagSet *flag.FlagSet) {
+	bindLocation(flagSet, &c.location)
+	c.search.bind(flagSet)
+	c.filter.bind(flagSet)
+	flagSet.StringVar(&c.format, "format", t4g.FormatRSS, "Format of the feed, one of rss, atom, json or ics")
+}
+
+// load parses the arguments of the command, loads the configuration and creates the client and feed input
+func (c *feedCommand) load(flagSet *flag.FlagSet, args []string) (*config.Config, *t4g.Client, t4g.FeedInput, error) {
+	cfg, err := c.search.loadConfig(flagSet, args)
+	if err != nil {
+		return nil, nil, t4g.FeedInput{}, err
+	}
+	err = checkFormat(c.format, t4g.FeedFormats...)
+	if err != nil {
+		return nil, nil, t4g.FeedInput{}, err
+	}
+	filter, err := c.filter.filter()
+	if err != nil {
+		return nil, nil, t4g.FeedInput{}, err
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, nil, t4g.FeedInput{}, err
+	}
+	portal, err := c.search.lookupPortal(client)
+	if err != nil {
+		return nil, nil, t4g.FeedInput{}, err
+	}
+
+	input := t4g.FeedInput{
+		Portal:   portal,
+		Location: lo.EmptyableToPtr(c.location),
+		Radius:   c.search.radiusOrNil(),
+		Filter:   filter,
+	}
+
+	return cfg, client, input, nil
+}
+
+// feed prints a feed of the current events of a location. The feed has no history,
+// so every event is a new item. Use export to keep the history of a feed.
+func feed(ctx context.Context, args []string, stdout io.Writer) error {
+	flagSet := newFlagSet("feed")
+	var command feedCommand
+	command.bind(flagSet)
+
+	cfg, client, input, err := command.load(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext(ctx)
+	defer stop()
+
+	feed := client.NewFeed(input)
+	err = feed.Update(ctx, cfg.EventPages)
+	var partialErr *t4g.PartialError
+	if errors.As(err, &partialErr) {
+		logPartialError(ctx, partialErr)
+	} else if err != nil {
+		return fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	feedContent, err := feed.Render(command.format)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(stdout, feedContent)
+	return err
+}
+
+// export refreshes a feed kept in the data directory and writes it to a file, or stdout if no file is set.
+// As the history of the feed is kept, items keep the time they were created between exports.
+func export(ctx context.Context, args []string, stdout io.Writer) error {
+	flagSet := newFlagSet("export")
+	var command feedCommand
+	command.bind(flagSet)
+	output := flagSet.String("output", "", "File to write the feed to. If not set, the feed is written to stdout.")
+
+	cfg, client, input, err := command.load(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if cfg.DataDir == "" {
+		return errors.New("data dir must be set to keep the history of exported feeds")
+	}
+
+	ctx, stop := signalContext(ctx)
+	defer stop()
+
+	// Stale feeds are still exported, as they are the latest feed there is
+	feed, err := client.FetchFeed(ctx, input, nil)
+	var partialErr *t4g.PartialError
+	var staleErr *t4g.StaleFeedError
+	switch {
+	case errors.As(err, &partialErr):
+		logPartialError(ctx, partialErr)
+	case errors.As(err, &staleErr):
+		slog.WarnContext(ctx, "Failed to refresh feed, exporting stale feed", "error", err)
+	case err != nil:
+		return fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	feedContent, err := feed.Render(command.format)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = io.WriteString(stdout, feedContent)
+		return err
+	}
+
+	return utils.WriteFile(*output, []byte(feedContent), 0o644)
+}
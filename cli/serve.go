@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/ahobsonsayers/t4g-feed/config"
+	"github.com/ahobsonsayers/t4g-feed/notifier"
+	"github.com/ahobsonsayers/t4g-feed/server"
+)
+
+// serve runs the feed server until an interrupt or terminate signal is received
+func serve(ctx context.Context, args []string, _ io.Writer) error {
+	flagSet := newFlagSet("serve")
+	cfg, err := config.LoadFlags(flagSet, args)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	t4gClient, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.DataDir != "" {
+		log.Printf("Storing feeds in %s\n", cfg.DataDir)
+	}
+
+	// signalCtx is cancelled when a shutdown signal is received, stopping background refresh.
+	// requestCtx is cancelled once in-flight requests have finished, or the shutdown timeout
+	// is reached, cancelling any feed updates that are still in progress.
+	signalCtx, stopSignals := signalContext(ctx)
+	defer stopSignals()
+	requestCtx, cancelRequests := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRequests()
+
+	waitForRefresh := func() {}
+	if cfg.RefreshInterval > 0 {
+		waitForRefresh = t4gClient.StartBackgroundRefresh(signalCtx, cfg.RefreshInterval, cfg.RefreshIdleTimeout)
+		log.Printf("Refreshing feeds in the background every %s\n", cfg.RefreshInterval)
+	}
+
+	var webhookNotifier *notifier.Notifier
+	if len(cfg.Webhooks) != 0 {
+		pollInterval := cfg.RefreshInterval
+		if pollInterval <= 0 {
+			pollInterval = cfg.DebounceTime
+		}
+		webhookNotifier, err = notifier.New(t4gClient, cfg.Webhooks)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook notifier: %w", err)
+		}
+		webhookNotifier.Start(requestCtx, pollInterval)
+		log.Printf("Notifying %d webhook subscriptions of new events\n", len(cfg.Webhooks))
+	}
+
+	router, err := server.NewRouter(cfg, t4gClient)
+	if err != nil {
+		return fmt.Errorf("failed to create router: %w", err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           router,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return requestCtx },
+	}
+
+	// Start the Server
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server listening on %s\n", cfg.Address)
+		serverErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("server exited with error: %w", err)
+	case <-signalCtx.Done():
+		stopSignals()
+	}
+
+	log.Printf("Shutting down, waiting up to %s for requests to finish\n", cfg.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.WithoutCancel(ctx), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	err = httpServer.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Println("Shutdown timeout reached, cancelling in-flight requests")
+		err = httpServer.Close()
+	}
+	if err != nil {
+		log.Printf("Failed to shut down server cleanly: %s\n", err)
+	}
+
+	// Cancel anything still using the request context, and wait for it to stop.
+	// Webhook deliveries in progress are given the rest of the shutdown timeout to finish.
+	cancelRequests()
+	waitForRefresh()
+	if webhookNotifier != nil {
+		webhookNotifier.Wait(shutdownCtx)
+	}
+
+	// Flush feed state so nothing is lost between restarts
+	err = t4gClient.SaveCachedFeeds()
+	if err != nil {
+		log.Printf("Failed to save feeds: %s\n", err)
+	}
+
+	log.Println("Server stopped")
+
+	return nil
+}
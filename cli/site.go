@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/ahobsonsayers/t4g-feed/site"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/samber/lo"
+)
+
+// generateSite generates a static site of the feeds of locations, keeping their history in a state file
+func generateSite(ctx context.Context, args []string, _ io.Writer) error {
+	flagSet := newFlagSet("site")
+	var locations stringsFlag
+	flagSet.Var(&locations, "location", "Location (or postcode) to generate a feed of. Can be repeated.")
+	var search searchFlags
+	search.bind(flagSet)
+	var filter filterFlags
+	filter.bind(flagSet)
+	outputDir := flagSet.String("output-dir", "", "Directory to write the site to")
+	baseUrl := flagSet.String(
+		"base-url", "", "Url the site is hosted at, used for links in the OPML. If not set, links are relative.",
+	)
+	stateFile := flagSet.String(
+		"state-file", "", "JSON file to keep the history of feeds in between runs. Must be outside the output dir.",
+	)
+
+	cfg, err := search.loadConfig(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		return errors.New("at least one location must be set")
+	}
+	if *outputDir == "" {
+		return errors.New("output dir must be set")
+	}
+	if *stateFile == "" {
+		return errors.New("state file must be set")
+	}
+	err = checkOutside(*stateFile, *outputDir)
+	if err != nil {
+		return err
+	}
+	eventFilter, err := filter.filter()
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	portal, err := search.lookupPortal(client)
+	if err != nil {
+		return err
+	}
+
+	inputs := lo.Map(locations, func(location string, _ int) t4g.FeedInput {
+		return t4g.FeedInput{
+			Portal:   portal,
+			Location: lo.ToPtr(location),
+			Radius:   search.radiusOrNil(),
+			Filter:   eventFilter,
+		}
+	})
+
+	ctx, stop := signalContext(ctx)
+	defer stop()
+
+	err = site.Generate(
+		ctx, client, t4g.NewFileFeedStore(*stateFile), inputs,
+		site.Options{OutputDir: *outputDir, BaseURL: *baseUrl, EventPages: cfg.EventPages},
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Generated site of %d feeds in %s\n", len(inputs), *outputDir)
+
+	return nil
+}
+
+// checkOutside checks the state file is outside the output dir, so the state of feeds is not published with the site
+func checkOutside(stateFile, outputDir string) error {
+	absStateFile, err := filepath.Abs(stateFile)
+	if err != nil {
+		return fmt.Errorf("invalid state file: %w", err)
+	}
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return fmt.Errorf("invalid output dir: %w", err)
+	}
+
+	relativePath, err := filepath.Rel(absOutputDir, absStateFile)
+	if err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return errors.New("state file must be outside the output dir, so it is not published")
+	}
+
+	return nil
+}
@@ -0,0 +1,392 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/notifier"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix     = "T4G_"
+	configFlag    = "config"
+	maxEventPages = 20
+)
+
+// Config is the configuration of the t4g feed server
+type Config struct {
+	Address string `yaml:"address"` // Address the server listens on
+	DataDir string `yaml:"dataDir"` // Directory to store feeds in. If empty, feeds are only kept in memory.
+
+	ReadTimeout       time.Duration `yaml:"readTimeout"`       // Maximum time to read a request
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"` // Maximum time to read request headers
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`      // Maximum time to handle a request and write its response
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`       // Maximum time to keep idle connections open
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"`   // Maximum time to wait for requests to finish on shutdown
+	ReadyGracePeriod  time.Duration `yaml:"readyGracePeriod"`  // Time the server stays ready after a failed scrape
+
+	DebounceTime   time.Duration `yaml:"debounceTime"`   // Time a feed is used for before being refreshed on request
+	MaxFeedItems   int           `yaml:"maxFeedItems"`   // Maximum number of items in a feed
+	EventPages     int           `yaml:"eventPages"`     // Number of event pages to get when refreshing a feed
+	MaxCachedFeeds int           `yaml:"maxCachedFeeds"` // Maximum number of feeds to keep in memory
+
+	UpstreamTimeout  time.Duration `yaml:"upstreamTimeout"`  // Timeout of each request to Tickets For Good
+	UpstreamAttempts int           `yaml:"upstreamAttempts"` // Maximum attempts of each request to Tickets For Good
+
+	Portals       map[string]string `yaml:"portals"`       // Portal name -> base url of portals feeds can be fetched from
+	DefaultPortal string            `yaml:"defaultPortal"` // Portal used when a feed does not choose one
+
+	RefreshInterval    time.Duration `yaml:"refreshInterval"`    // Interval to refresh feeds in the background
+	RefreshIdleTimeout time.Duration `yaml:"refreshIdleTimeout"` // Time after which unrequested feeds stop refreshing
+
+	WebhooksFile string                  `yaml:"webhooksFile"` // JSON file of webhook subscriptions
+	Webhooks     []notifier.Subscription `yaml:"webhooks"`     // Webhook subscriptions
+}
+
+// Default returns the default configuration
+func Default() Config {
+	upstreamClient := t4g.DefaultUpstreamClient()
+	return Config{
+		Address:            "0.0.0.0:5656",
+		ReadTimeout:        15 * time.Second,
+		ReadHeaderTimeout:  5 * time.Second,
+		WriteTimeout:       time.Minute,
+		IdleTimeout:        2 * time.Minute,
+		ShutdownTimeout:    30 * time.Second,
+		DebounceTime:       5 * time.Minute,
+		MaxFeedItems:       t4g.DefaultMaxFeedItems,
+		EventPages:         t4g.DefaultEventPages,
+		MaxCachedFeeds:     t4g.DefaultMaxCachedFeeds,
+		UpstreamTimeout:    upstreamClient.RequestTimeout,
+		UpstreamAttempts:   upstreamClient.MaxAttempts,
+		Portals:            t4g.DefaultPortals(),
+		DefaultPortal:      t4g.DefaultPortalName,
+		RefreshInterval:    5 * time.Minute,
+		RefreshIdleTimeout: 24 * time.Hour,
+	}
+}
+
+// Load loads the configuration from (in order of increasing precedence) the defaults,
+// an optional yaml file, environment variables and command line arguments.
+//
+// The yaml file is set with the -config flag or T4G_CONFIG environment variable.
+// Each flag can also be set with an environment variable of the flag name in upper
+// snake case, prefixed with T4G_ e.g. -data-dir can be set with T4G_DATA_DIR.
+func Load(args []string) (*Config, error) {
+	return LoadFlags(flag.NewFlagSet("t4g-feed", flag.ContinueOnError), args)
+}
+
+// LoadFlags loads the configuration in the same way as Load, but parses the arguments with a
+// flag set that can have other flags (e.g. of a command) bound to it. Only the flags of the
+// configuration can be set with environment variables.
+func LoadFlags(flagSet *flag.FlagSet, args []string) (*Config, error) {
+	config := Default()
+
+	// Flags bound before the configuration flags are not set from environment variables
+	otherFlags := map[string]bool{}
+	flagSet.VisitAll(func(f *flag.Flag) { otherFlags[f.Name] = true })
+
+	configPath := flagSet.String(configFlag, os.Getenv(envName(configFlag)), "YAML config file")
+	config.bindFlags(flagSet)
+
+	// Flags are applied last so they take precedence. Record the values flags are set to,
+	// so they can be set again after being overwritten by the config file. The values passed
+	// are recorded rather than the resulting flag values, as flags such as -portals add to
+	// the existing value, which would otherwise reset values set by the config file.
+	var setFlags []setFlag
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if f.Name != configFlag && !otherFlags[f.Name] {
+			f.Value = &recordingValue{Value: f.Value, setFlags: &setFlags}
+		}
+	})
+
+	err := flagSet.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop recording, so environment variables are not recorded as flags
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if recordingValue, ok := f.Value.(*recordingValue); ok {
+			f.Value = recordingValue.Value
+		}
+	})
+
+	if *configPath != "" {
+		err = config.loadFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply environment variables then flags
+	var errs error
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if f.Name == configFlag || otherFlags[f.Name] {
+			return
+		}
+
+		envValue, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+
+		err := f.Value.Set(envValue)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid value %q for %s: %w", envValue, envName(f.Name), err))
+		}
+	})
+	if errs != nil {
+		return nil, errs
+	}
+
+	for _, setFlag := range setFlags {
+		err = setFlag.value.Set(setFlag.rawValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.WebhooksFile != "" {
+		subscriptions, err := notifier.LoadSubscriptions(config.WebhooksFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load webhook subscriptions: %w", err)
+		}
+		config.Webhooks = append(config.Webhooks, subscriptions...)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Address == "" {
+		errs = append(errs, errors.New("address must be set"))
+	}
+	if c.ReadTimeout < 0 || c.ReadHeaderTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+	if c.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("shutdown timeout must not be negative"))
+	}
+	if c.ReadyGracePeriod < 0 {
+		errs = append(errs, errors.New("ready grace period must not be negative"))
+	}
+	if c.DebounceTime < 0 {
+		errs = append(errs, errors.New("debounce time must not be negative"))
+	}
+	if c.MaxFeedItems < 1 {
+		errs = append(errs, errors.New("max feed items must be at least 1"))
+	}
+	if c.EventPages < 1 || c.EventPages > maxEventPages {
+		errs = append(errs, fmt.Errorf("event pages must be between 1 and %d", maxEventPages))
+	}
+	if c.MaxCachedFeeds < 1 {
+		errs = append(errs, errors.New("max cached feeds must be at least 1"))
+	}
+	if c.UpstreamTimeout < 0 {
+		errs = append(errs, errors.New("upstream timeout must not be negative"))
+	}
+	if c.UpstreamAttempts < 1 {
+		errs = append(errs, errors.New("upstream attempts must be at least 1"))
+	}
+	if _, ok := c.Portals[c.DefaultPortal]; !ok {
+		errs = append(errs, fmt.Errorf("default portal %q must be one of the portals", c.DefaultPortal))
+	}
+	for name, baseUrl := range c.Portals {
+		_, err := t4g.NewPortal(name, baseUrl)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.RefreshInterval < 0 {
+		errs = append(errs, errors.New("refresh interval must not be negative"))
+	}
+	if c.RefreshInterval > 0 && c.RefreshIdleTimeout <= 0 {
+		errs = append(errs, errors.New("refresh idle timeout must be positive when refresh is enabled"))
+	}
+	for idx, subscription := range c.Webhooks {
+		err := subscription.Validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %d: %w", idx, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ClientOptions returns the options of the t4g client used to fetch feeds.
+// The feed store is not included, as creating it can fail.
+func (c *Config) ClientOptions() []t4g.Option {
+	upstream := t4g.DefaultUpstreamClient()
+	upstream.RequestTimeout = c.UpstreamTimeout
+	upstream.MaxAttempts = c.UpstreamAttempts
+
+	return []t4g.Option{
+		t4g.WithUpstreamClient(upstream),
+		t4g.WithPortals(c.Portals, c.DefaultPortal),
+		t4g.WithEventPages(c.EventPages),
+		t4g.WithMaxFeedItems(c.MaxFeedItems),
+		t4g.WithMaxCachedFeeds(c.MaxCachedFeeds),
+	}
+}
+
+func (c *Config) bindFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&c.Address, "address", c.Address, "Address the server listens on")
+	flagSet.StringVar(
+		&c.DataDir, "data-dir", c.DataDir,
+		"Directory to store feeds in so they survive restarts. If not set, feeds are only kept in memory.",
+	)
+	flagSet.DurationVar(&c.ReadTimeout, "read-timeout", c.ReadTimeout, "Maximum time to read a request")
+	flagSet.DurationVar(
+		&c.ReadHeaderTimeout, "read-header-timeout", c.ReadHeaderTimeout,
+		"Maximum time to read request headers",
+	)
+	flagSet.DurationVar(
+		&c.WriteTimeout, "write-timeout", c.WriteTimeout,
+		"Maximum time to handle a request and write its response",
+	)
+	flagSet.DurationVar(&c.IdleTimeout, "idle-timeout", c.IdleTimeout, "Maximum time to keep idle connections open")
+	flagSet.DurationVar(
+		&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout,
+		"Maximum time to wait for in-flight requests to finish on shutdown before cancelling them",
+	)
+	flagSet.DurationVar(
+		&c.ReadyGracePeriod, "ready-grace-period", c.ReadyGracePeriod,
+		"Time since the last successful scrape of Tickets For Good that the server is still ready if a scrape fails",
+	)
+	flagSet.DurationVar(
+		&c.DebounceTime, "debounce-time", c.DebounceTime,
+		"Time a feed is used for before it is refreshed on request",
+	)
+	flagSet.IntVar(&c.MaxFeedItems, "max-feed-items", c.MaxFeedItems, "Maximum number of items in a feed")
+	flagSet.IntVar(&c.EventPages, "event-pages", c.EventPages, "Number of event pages to get when refreshing a feed")
+	flagSet.IntVar(&c.MaxCachedFeeds, "max-cached-feeds", c.MaxCachedFeeds, "Maximum number of feeds to keep in memory")
+	flagSet.DurationVar(
+		&c.UpstreamTimeout, "upstream-timeout", c.UpstreamTimeout,
+		"Timeout of each request to Tickets For Good. Set to 0 for no timeout.",
+	)
+	flagSet.IntVar(
+		&c.UpstreamAttempts, "upstream-attempts", c.UpstreamAttempts,
+		"Maximum number of attempts of each request to Tickets For Good, retrying server errors and rate limits",
+	)
+	flagSet.Var(
+		(*portalsFlag)(&c.Portals), "portals",
+		"Comma separated portals feeds can be fetched from, as name=url e.g. mirror=http://localhost:8080. "+
+			"These are added to the default nhs portal, which can be overridden.",
+	)
+	flagSet.StringVar(
+		&c.DefaultPortal, "default-portal", c.DefaultPortal,
+		"Portal used when a feed does not choose one",
+	)
+	flagSet.DurationVar(
+		&c.RefreshInterval, "refresh-interval", c.RefreshInterval,
+		"Interval to refresh requested feeds in the background. Set to 0 to disable background refresh.",
+	)
+	flagSet.DurationVar(
+		&c.RefreshIdleTimeout, "refresh-idle-timeout", c.RefreshIdleTimeout,
+		"Stop refreshing feeds in the background if they have not been requested for this long",
+	)
+	flagSet.StringVar(
+		&c.WebhooksFile, "webhooks-file", c.WebhooksFile,
+		"JSON file of webhook subscriptions to notify when new events are added to a location",
+	)
+}
+
+func (c *Config) loadFile(path string) error {
+	configFile, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer configFile.Close()
+
+	decoder := yaml.NewDecoder(configFile)
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(c)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	return nil
+}
+
+// setFlag is a flag that has been set on the command line, and the value it was set to
+type setFlag struct {
+	value    flag.Value
+	rawValue string
+}
+
+// recordingValue is a flag value that records the values it is set to on the command line
+type recordingValue struct {
+	flag.Value
+	setFlags *[]setFlag
+}
+
+func (v *recordingValue) Set(value string) error {
+	err := v.Value.Set(value)
+	if err != nil {
+		return err
+	}
+
+	*v.setFlags = append(*v.setFlags, setFlag{value: v.Value, rawValue: value})
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be set without a value
+func (v *recordingValue) IsBoolFlag() bool {
+	boolValue, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolValue.IsBoolFlag()
+}
+
+// portalsFlag is a flag of comma separated portals, as name=url. Portals are added to the existing portals.
+type portalsFlag map[string]string
+
+func (f *portalsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	portals := make([]string, 0, len(*f))
+	for name, baseUrl := range *f {
+		portals = append(portals, name+"="+baseUrl)
+	}
+	sort.Strings(portals)
+
+	return strings.Join(portals, ",")
+}
+
+func (f *portalsFlag) Set(value string) error {
+	if *f == nil {
+		*f = map[string]string{}
+	}
+
+	for _, portal := range strings.Split(value, ",") {
+		name, baseUrl, ok := strings.Cut(strings.TrimSpace(portal), "=")
+		if !ok || name == "" || baseUrl == "" {
+			return fmt.Errorf("invalid portal %q: must be name=url", portal)
+		}
+		(*f)[name] = baseUrl
+	}
+
+	return nil
+}
+
+// envName returns the name of the environment variable of a flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
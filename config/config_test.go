@@ -0,0 +1,132 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	require.Equal(t, config.Default(), *cfg)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configPath, []byte(`
+address: 127.0.0.1:8080
+maxFeedItems: 50
+eventPages: 3
+debounceTime: 1m
+webhooks:
+  - location: london
+    url: https://example.com/webhook
+`), 0o600)
+	require.NoError(t, err)
+
+	t.Setenv("T4G_CONFIG", configPath)
+	t.Setenv("T4G_EVENT_PAGES", "4")
+	t.Setenv("T4G_DEBOUNCE_TIME", "2m")
+
+	cfg, err := config.Load([]string{"-debounce-time", "3m"})
+	require.NoError(t, err)
+
+	require.Equal(t, "127.0.0.1:8080", cfg.Address)   // From file
+	require.Equal(t, 50, cfg.MaxFeedItems)            // From file
+	require.Equal(t, 4, cfg.EventPages)               // Env overrides file
+	require.Equal(t, 3*time.Minute, cfg.DebounceTime) // Flag overrides env and file
+	require.Equal(t, 10, cfg.MaxCachedFeeds)          // Default
+	require.Len(t, cfg.Webhooks, 1)
+}
+
+func TestLoadPortals(t *testing.T) {
+	t.Setenv("T4G_PORTALS", "mirror=http://localhost:8080")
+
+	cfg, err := config.Load([]string{"-portals", "other=https://other.example.com", "-default-portal", "mirror"})
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		map[string]string{
+			"nhs":    "https://nhs.ticketsforgood.co.uk",
+			"mirror": "http://localhost:8080",
+			"other":  "https://other.example.com",
+		},
+		cfg.Portals,
+	)
+	require.Equal(t, "mirror", cfg.DefaultPortal)
+
+	_, err = config.Load([]string{"-default-portal", "unknown"})
+	require.ErrorContains(t, err, "default portal")
+
+	_, err = config.Load([]string{"-portals", "mirror"})
+	require.ErrorContains(t, err, "must be name=url")
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := config.Load([]string{"-event-pages", "0"})
+	require.ErrorContains(t, err, "event pages")
+
+	t.Setenv("T4G_REFRESH_INTERVAL", "soon")
+	_, err = config.Load(nil)
+	require.ErrorContains(t, err, "T4G_REFRESH_INTERVAL")
+}
+
+func TestLoadFlags(t *testing.T) {
+	t.Setenv("T4G_LOCATION", "leeds")
+	t.Setenv("T4G_EVENT_PAGES", "4")
+
+	flagSet := flag.NewFlagSet("events", flag.ContinueOnError)
+	location := flagSet.String("location", "", "Location to search")
+
+	cfg, err := config.LoadFlags(flagSet, []string{"-location", "london", "-max-feed-items", "20"})
+	require.NoError(t, err)
+	require.Equal(t, "london", *location)
+	require.Equal(t, 4, cfg.EventPages)
+	require.Equal(t, 20, cfg.MaxFeedItems)
+
+	// Other flags are not set from environment variables
+	flagSet = flag.NewFlagSet("events", flag.ContinueOnError)
+	location = flagSet.String("location", "", "Location to search")
+
+	_, err = config.LoadFlags(flagSet, nil)
+	require.NoError(t, err)
+	require.Empty(t, *location)
+}
+
+func TestLoadPortalsFileAndFlag(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configPath, []byte(`
+portals:
+  nhs: http://localhost:9999
+  mirror: http://localhost:8080
+`), 0o600)
+	require.NoError(t, err)
+
+	// Portals of flags are added to the portals of the file, rather than resetting them
+	cfg, err := config.Load([]string{
+		"-config", configPath, "-portals", "x=http://x.example", "-portals", "y=http://y.example",
+	})
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		map[string]string{
+			"nhs":    "http://localhost:9999",
+			"mirror": "http://localhost:8080",
+			"x":      "http://x.example",
+			"y":      "http://y.example",
+		},
+		cfg.Portals,
+	)
+
+	// Portals of flags override portals of the file with the same name
+	cfg, err = config.Load([]string{"-config", configPath, "-portals", "mirror=http://localhost:7070"})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:9999", cfg.Portals["nhs"])
+	require.Equal(t, "http://localhost:7070", cfg.Portals["mirror"])
+}
@@ -1,26 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
-	"net/http"
+	"os"
 
-	"github.com/ahobsonsayers/t4g-feed/server"
+	"github.com/ahobsonsayers/t4g-feed/cli"
 )
 
 //go:generate go run github.com/deepmap/oapi-codegen/v2/cmd/oapi-codegen -config .oapigen.yaml schema/openapi.yaml
 
-const serverAddress = "0.0.0.0:5656"
-
 func main() {
-	router, err := server.NewRouter()
-	if err != nil {
-		log.Fatalf("Failed to create router: %s", err)
+	err := cli.Run(context.Background(), os.Args[1:], os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-
-	// Start the Server
-	log.Printf("Server listening on %s\n", serverAddress)
-	err = http.ListenAndServe(serverAddress, router)
 	if err != nil {
-		log.Fatalf("Server exited with error: %s", err)
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,341 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/utils"
+	"github.com/samber/lo"
+)
+
+const (
+	SignatureHeader = "X-T4G-Signature"
+	EventHeader     = "X-T4G-Event"
+
+	newEventsEvent = "new-events"
+
+	maxDeliveryAttempts = 5
+	initialBackoff      = time.Second
+	maxBackoff          = time.Minute
+	deliveryTimeout     = 10 * time.Second
+	feedPollDebounce    = time.Minute
+)
+
+// Subscription is a subscription to new events of a location, delivered to a webhook url
+type Subscription struct {
+	Location string `json:"location" yaml:"location"`
+	Radius   *int   `json:"radius,omitempty" yaml:"radius,omitempty"` // Search radius in miles
+	Portal   string `json:"portal,omitempty" yaml:"portal,omitempty"` // Portal of the feed. If empty, the default portal.
+	URL      string `json:"url" yaml:"url"`                           // Webhook url new events are posted to
+	Secret   string `json:"secret,omitempty" yaml:"secret,omitempty"` // Secret used to sign payloads
+}
+
+// Validate validates the subscription
+func (s Subscription) Validate() error {
+	if s.Location == "" {
+		return errors.New("subscription has no location")
+	}
+
+	if s.Radius != nil && *s.Radius < 1 {
+		return errors.New("subscription radius must be at least 1")
+	}
+
+	webhookUrl, err := url.Parse(s.URL)
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		return fmt.Errorf("subscription has invalid url %q", s.URL)
+	}
+
+	return nil
+}
+
+// feedInput returns the input of the feed subscribed to, looking up its portal using a t4g client
+func (s Subscription) feedInput(t4gClient *t4g.Client) (t4g.FeedInput, error) {
+	input := t4g.FeedInput{
+		Location: lo.ToPtr(s.Location),
+		Radius:   s.Radius,
+	}
+
+	if s.Portal != "" {
+		portal, err := t4gClient.LookupPortal(s.Portal)
+		if err != nil {
+			return t4g.FeedInput{}, err
+		}
+		input.Portal = &portal
+	}
+
+	return input, nil
+}
+
+// Payload is the json payload posted to webhooks
+type Payload struct {
+	Location string         `json:"location"`
+	Radius   *int           `json:"radius,omitempty"`
+	Portal   string         `json:"portal,omitempty"`
+	Events   []PayloadEvent `json:"events"`
+}
+
+// PayloadEvent is an event in a webhook payload
+type PayloadEvent struct {
+	Id       int        `json:"id"`
+	Title    string     `json:"title"`
+	Link     string     `json:"link"`
+	Image    string     `json:"image"`
+	Location string     `json:"location"`
+	Date     string     `json:"date"`
+	Category string     `json:"category"`
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+}
+
+func newPayloadEvent(event t4g.Event) PayloadEvent {
+	return PayloadEvent{
+		Id:       event.Id,
+		Title:    event.Title,
+		Link:     event.Link,
+		Image:    event.Image,
+		Location: event.Location,
+		Date:     event.Date,
+		Category: event.Category,
+		StartsAt: lo.Ternary(event.StartsAt.IsZero(), nil, lo.ToPtr(event.StartsAt)),
+		EndsAt:   lo.Ternary(event.EndsAt.IsZero(), nil, lo.ToPtr(event.EndsAt)),
+	}
+}
+
+// Notifier posts new events of subscribed locations to webhooks
+type Notifier struct {
+	t4gClient     *t4g.Client
+	subscriptions []Subscription
+	feedInputs    []t4g.FeedInput // Inputs of the feeds subscribed to, in the same order as the subscriptions
+	client        *http.Client
+
+	poller sync.WaitGroup
+
+	// deliveryCtx is cancelled if deliveries do not finish in time on shutdown.
+	// It is separate to the context polling is started with, so deliveries in progress
+	// when polling is stopped can still finish.
+	deliveryCtx    context.Context
+	cancelDelivery context.CancelFunc
+	deliveries     sync.WaitGroup
+	stopped        bool // Whether the notifier has been stopped, so no more deliveries can be started
+	stoppedMutex   sync.Mutex
+}
+
+// New creates a notifier for subscriptions to the feeds of a t4g client.
+// The portals of the subscriptions must be allowed by the client.
+func New(t4gClient *t4g.Client, subscriptions []Subscription) (*Notifier, error) {
+	feedInputs := make([]t4g.FeedInput, 0, len(subscriptions))
+	for idx, subscription := range subscriptions {
+		feedInput, err := subscription.feedInput(t4gClient)
+		if err != nil {
+			return nil, fmt.Errorf("subscription %d: %w", idx, err)
+		}
+		feedInputs = append(feedInputs, feedInput)
+	}
+
+	deliveryCtx, cancelDelivery := context.WithCancel(context.Background())
+
+	return &Notifier{
+		t4gClient:      t4gClient,
+		subscriptions:  subscriptions,
+		feedInputs:     feedInputs,
+		client:         &http.Client{Timeout: deliveryTimeout},
+		deliveryCtx:    deliveryCtx,
+		cancelDelivery: cancelDelivery,
+	}, nil
+}
+
+// LoadSubscriptions loads subscriptions from a json file containing a list of subscriptions
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	subscriptionsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	err = json.Unmarshal(subscriptionsBytes, &subscriptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode subscriptions: %w", err)
+	}
+
+	for idx, subscription := range subscriptions {
+		err := subscription.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("subscription %d: %w", idx, err)
+		}
+	}
+
+	return subscriptions, nil
+}
+
+// Start starts the notifier. It listens for new events being added to feeds, and keeps the feeds
+// of subscribed locations updated by fetching them every poll interval until the context is cancelled.
+func (n *Notifier) Start(ctx context.Context, pollInterval time.Duration) {
+	n.t4gClient.AddNewEventsListener(n.NotifyNewEvents)
+
+	n.poller.Add(1)
+	go func() {
+		defer n.poller.Done()
+		n.pollFeeds(ctx, pollInterval)
+	}()
+}
+
+// Wait waits for polling to stop once the context the notifier was started with is cancelled, then stops
+// the notifier and waits for in progress deliveries to finish. If the context passed is cancelled before
+// deliveries finish, they are cancelled. New events are not delivered once the notifier has been stopped.
+func (n *Notifier) Wait(ctx context.Context) {
+	n.poller.Wait()
+
+	n.stoppedMutex.Lock()
+	n.stopped = true
+	n.stoppedMutex.Unlock()
+
+	delivered := make(chan struct{})
+	go func() {
+		n.deliveries.Wait()
+		close(delivered)
+	}()
+
+	select {
+	case <-delivered:
+	case <-ctx.Done():
+		slog.Warn("Timed out waiting for webhook deliveries, cancelling them")
+		n.cancelDelivery()
+		<-delivered
+	}
+	n.cancelDelivery()
+}
+
+// NotifyNewEvents posts new events to the webhooks of subscriptions to the feed input.
+// Deliveries happen in the background, so this does not block.
+func (n *Notifier) NotifyNewEvents(ctx context.Context, input t4g.FeedInput, events []t4g.Event) {
+	// Deliveries are only started while the notifier has not been stopped,
+	// so none are started while waiting for deliveries to finish
+	n.stoppedMutex.Lock()
+	defer n.stoppedMutex.Unlock()
+
+	for idx, subscription := range n.subscriptions {
+		if n.t4gClient.FeedKey(n.feedInputs[idx]) != n.t4gClient.FeedKey(input) {
+			continue
+		}
+
+		if n.stopped {
+			slog.WarnContext(ctx, "Notifier has stopped, not delivering webhook", "url", subscription.URL)
+			continue
+		}
+
+		payload := Payload{
+			Location: subscription.Location,
+			Radius:   subscription.Radius,
+			Portal:   subscription.Portal,
+			Events:   lo.Map(events, func(event t4g.Event, _ int) PayloadEvent { return newPayloadEvent(event) }),
+		}
+
+		n.deliveries.Add(1)
+		go func(subscription Subscription) {
+			defer n.deliveries.Done()
+
+			err := n.deliver(n.deliveryCtx, subscription, payload)
+			if err != nil {
+				slog.Error("Failed to deliver webhook", "url", subscription.URL, "error", err)
+			}
+		}(subscription)
+	}
+}
+
+// pollFeeds fetches the feeds of subscribed locations every interval so they are kept updated
+func (n *Notifier) pollFeeds(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for idx, subscription := range n.subscriptions {
+			_, err := n.t4gClient.FetchFeed(ctx, n.feedInputs[idx], lo.ToPtr(feedPollDebounce))
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("Failed to fetch subscribed feed", "location", subscription.Location, "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver posts a payload to the webhook of a subscription, retrying with exponential backoff
+func (n *Notifier) deliver(ctx context.Context, subscription Subscription, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	retryOptions := utils.RetryOptions{
+		MaxAttempts:    maxDeliveryAttempts,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+	}
+
+	return utils.Retry(ctx, retryOptions, func(ctx context.Context) (time.Duration, error) {
+		return n.post(ctx, subscription, body)
+	})
+}
+
+// post posts a body to the webhook of a subscription. If the webhook
+// responds with a retry after header, its duration is returned.
+func (n *Notifier) post(ctx context.Context, subscription Subscription, body []byte) (time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, utils.Permanent(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, newEventsEvent)
+	if subscription.Secret != "" {
+		request.Header.Set(SignatureHeader, Sign(subscription.Secret, body))
+	}
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	httpError := utils.HTTPResponseError(response)
+	if httpError == nil {
+		return 0, nil
+	}
+
+	// Only retry server errors and rate limiting
+	if response.StatusCode < http.StatusInternalServerError && response.StatusCode != http.StatusTooManyRequests {
+		return 0, utils.Permanent(httpError)
+	}
+
+	retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || retryAfter < 0 {
+		return 0, httpError
+	}
+
+	return min(time.Duration(retryAfter)*time.Second, maxBackoff), httpError
+}
+
+// Sign returns the signature of a body using a secret. The signature is the
+// hex encoded HMAC-SHA256 of the body, prefixed with the algorithm e.g. sha256=abc123
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
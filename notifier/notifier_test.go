@@ -0,0 +1,161 @@
+package notifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/notifier"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierDeliversSignedPayloadWithRetries(t *testing.T) {
+	var requestsMutex sync.Mutex
+	var requests int
+	var receivedPayload notifier.Payload
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestsMutex.Lock()
+		defer requestsMutex.Unlock()
+
+		// Fail the first request to test retries
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, notifier.Sign("secret", body), r.Header.Get(notifier.SignatureHeader))
+
+		err = json.Unmarshal(body, &receivedPayload)
+		require.NoError(t, err)
+	}))
+	defer webhook.Close()
+
+	subscriptions := []notifier.Subscription{
+		{Location: "London", URL: webhook.URL, Secret: "secret"},
+		{Location: "Leeds", URL: webhook.URL, Secret: "secret"},
+	}
+	client, err := t4g.NewClient()
+	require.NoError(t, err)
+	n, err := notifier.New(client, subscriptions)
+	require.NoError(t, err)
+
+	events := []t4g.Event{{Id: 123, Title: "Hamlet"}}
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("london")}, events)
+	n.Wait(context.Background())
+
+	require.Equal(t, 2, requests)
+	require.Equal(t, "London", receivedPayload.Location)
+	require.Len(t, receivedPayload.Events, 1)
+	require.Equal(t, 123, receivedPayload.Events[0].Id)
+	require.Equal(t, "Hamlet", receivedPayload.Events[0].Title)
+}
+
+func TestNotifierIgnoresOtherFeeds(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		require.Fail(t, "webhook should not be called")
+	}))
+	defer webhook.Close()
+
+	client, err := t4g.NewClient()
+	require.NoError(t, err)
+	n, err := notifier.New(client, []notifier.Subscription{{Location: "London", URL: webhook.URL}})
+	require.NoError(t, err)
+
+	events := []t4g.Event{{Id: 123, Title: "Hamlet"}}
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("london"), Radius: lo.ToPtr(5)}, events)
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("leeds")}, events)
+	n.Wait(context.Background())
+}
+
+func TestNotifierPortal(t *testing.T) {
+	var requests atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { requests.Add(1) }))
+	defer webhook.Close()
+
+	client := t4gtest.NewServer(t).Client(t)
+	subscriptions := []notifier.Subscription{{Location: "London", Portal: t4gtest.MirrorPortal, URL: webhook.URL}}
+	n, err := notifier.New(client, subscriptions)
+	require.NoError(t, err)
+
+	mirror, err := client.LookupPortal(t4gtest.MirrorPortal)
+	require.NoError(t, err)
+
+	// Only new events of the feed of the subscribed portal are delivered
+	events := []t4g.Event{{Id: 123, Title: "Hamlet"}}
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("london")}, events)
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Portal: &mirror, Location: lo.ToPtr("london")}, events)
+	n.Wait(context.Background())
+	require.Equal(t, int32(1), requests.Load())
+
+	_, err = notifier.New(client, []notifier.Subscription{{Location: "London", Portal: "other", URL: webhook.URL}})
+	require.ErrorIs(t, err, t4g.ErrUnknownPortal)
+}
+
+func TestNotifierWaitDrainsDeliveries(t *testing.T) {
+	release := make(chan struct{})
+	var delivered atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+		delivered.Add(1)
+	}))
+	defer webhook.Close()
+
+	client := t4gtest.NewServer(t).Client(t)
+	n, err := notifier.New(client, []notifier.Subscription{{Location: "London", URL: webhook.URL}})
+	require.NoError(t, err)
+
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	n.Start(pollCtx, time.Hour)
+
+	events := []t4g.Event{{Id: 123, Title: "Hamlet"}}
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("london")}, events)
+
+	// Deliveries in progress should finish after polling is stopped
+	stopPolling()
+	waited := make(chan struct{})
+	go func() {
+		n.Wait(context.Background())
+		close(waited)
+	}()
+	close(release)
+	<-waited
+	require.Equal(t, int32(1), delivered.Load())
+
+	// New events are not delivered once the notifier has stopped
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("london")}, events)
+	require.Equal(t, int32(1), delivered.Load())
+}
+
+func TestNotifierWaitCancelsDeliveries(t *testing.T) {
+	release := make(chan struct{})
+	webhook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release }))
+	defer webhook.Close()
+	defer close(release)
+
+	client, err := t4g.NewClient()
+	require.NoError(t, err)
+	n, err := notifier.New(client, []notifier.Subscription{{Location: "London", URL: webhook.URL}})
+	require.NoError(t, err)
+
+	events := []t4g.Event{{Id: 123, Title: "Hamlet"}}
+	n.NotifyNewEvents(context.Background(), t4g.FeedInput{Location: lo.ToPtr("london")}, events)
+
+	// Deliveries that do not finish in time are cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	n.Wait(ctx)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// feedCacheHeaders returns the cache headers of a feed response at a time
+func feedCacheHeaders(
+	content string,
+	updatedAt, refreshedAt, now time.Time,
+	debounceTime time.Duration,
+) T4g200ResponseHeaders {
+	// Readers can cache the feed until it may next be refreshed
+	maxAge := debounceTime - now.Sub(refreshedAt)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	headers := T4g200ResponseHeaders{
+		ETag:         contentETag(content),
+		CacheControl: fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())),
+		Vary:         "Accept", // The format of the feed can be negotiated
+	}
+	if !updatedAt.IsZero() {
+		headers.LastModified = updatedAt.UTC().Format(http.TimeFormat)
+	}
+
+	return headers
+}
+
+// omitEmptyHeadersT4gResponse is a feed response that leaves out headers without a value. Generated responses
+// set every header declared in the spec, but some are only set on some responses e.g. those of stale feeds.
+type omitEmptyHeadersT4gResponse struct {
+	T4gResponseObject
+}
+
+func (response omitEmptyHeadersT4gResponse) VisitT4gResponse(w http.ResponseWriter) error {
+	return response.T4gResponseObject.VisitT4gResponse(omitEmptyHeadersWriter{w})
+}
+
+// omitEmptyHeadersWriter is a response writer that removes headers without a value before they are written
+type omitEmptyHeadersWriter struct {
+	http.ResponseWriter
+}
+
+func (w omitEmptyHeadersWriter) WriteHeader(statusCode int) {
+	for key, values := range w.Header() {
+		if len(values) == 1 && values[0] == "" {
+			w.Header().Del(key)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// contentETag returns a strong etag of content
+func contentETag(content string) string {
+	contentHash := sha256.Sum256([]byte(content))
+	return fmt.Sprintf("%q", hex.EncodeToString(contentHash[:16]))
+}
+
+// isNotModified returns whether a resource has not been modified, according to the
+// If-None-Match and If-Modified-Since request headers. As per RFC 9110, If-Modified-Since
+// is ignored if If-None-Match is set.
+func isNotModified(ifNoneMatch, ifModifiedSince *string, headers T4g200ResponseHeaders) bool {
+	if ifNoneMatch != nil {
+		return etagMatches(*ifNoneMatch, headers.ETag)
+	}
+
+	if ifModifiedSince != nil && headers.LastModified != "" {
+		modifiedSince, err := http.ParseTime(*ifModifiedSince)
+		if err != nil {
+			return false
+		}
+
+		lastModified, err := http.ParseTime(headers.LastModified)
+		if err != nil {
+			return false
+		}
+
+		return !lastModified.After(modifiedSince)
+	}
+
+	return false
+}
+
+// etagMatches returns whether an etag matches any in an If-None-Match header value, using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, matchETag := range strings.Split(ifNoneMatch, ",") {
+		matchETag = strings.TrimSpace(matchETag)
+		if matchETag == "*" || strings.TrimPrefix(matchETag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNotModified(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 20, 19, 30, 0, 0, time.UTC)
+	headers := feedCacheHeaders("<rss></rss>", updatedAt, updatedAt, updatedAt.Add(time.Minute), 5*time.Minute)
+	require.Equal(t, "Sat, 20 Jan 2024 19:30:00 GMT", headers.LastModified)
+	require.Equal(t, "public, max-age=240", headers.CacheControl)
+
+	// If-None-Match
+	require.True(t, isNotModified(&headers.ETag, nil, headers))
+	require.True(t, isNotModified(lo.ToPtr(`"other", W/`+headers.ETag), nil, headers))
+	require.True(t, isNotModified(lo.ToPtr("*"), nil, headers))
+	require.False(t, isNotModified(lo.ToPtr(`"other"`), nil, headers))
+
+	// If-Modified-Since
+	require.True(t, isNotModified(nil, lo.ToPtr("Sat, 20 Jan 2024 19:30:00 GMT"), headers))
+	require.False(t, isNotModified(nil, lo.ToPtr("Sat, 20 Jan 2024 19:29:59 GMT"), headers))
+	require.False(t, isNotModified(nil, lo.ToPtr("not a date"), headers))
+
+	// If-None-Match takes precedence over If-Modified-Since
+	require.False(t, isNotModified(lo.ToPtr(`"other"`), lo.ToPtr("Sat, 20 Jan 2024 19:30:00 GMT"), headers))
+
+	require.False(t, isNotModified(nil, nil, headers))
+}
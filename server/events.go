@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/samber/lo"
+)
+
+const defaultEventsLimit = 20
+
+func (s *server) Events(ctx context.Context, request EventsRequestObject) (EventsResponseObject, error) {
+	limit := lo.FromPtrOr(request.Params.Limit, defaultEventsLimit)
+
+	var cursorEventId *int
+	if request.Params.Cursor != nil {
+		eventId, err := decodeEventsCursor(*request.Params.Cursor)
+		if err != nil {
+			return Events400JSONResponse{ErrorJSONResponse{Error: err.Error()}}, nil
+		}
+		cursorEventId = &eventId
+	}
+
+	portal, err := s.lookupPortal(request.Params.Portal)
+	if err != nil {
+		return Events400JSONResponse{ErrorJSONResponse{Error: err.Error()}}, nil
+	}
+
+	feedInput := t4g.FeedInput{
+		Portal:   portal,
+		Location: request.Params.Location,
+		Radius:   request.Params.Radius,
+	}
+
+	feed, stale, err := s.fetchFeed(ctx, feedInput)
+	if err != nil {
+		return Events503JSONResponse{unavailableResponse(err)}, nil
+	}
+
+	// Feed events are ordered newest (largest id) first. Only the events cached in
+	// the feed are paginated, rather than every event listed on Tickets For Good.
+	feedEvents := feed.Events()
+	response := Events200JSONResponse{
+		Limit:       limit,
+		CachedTotal: len(feedEvents),
+	}
+
+	var pageEvents []t4g.Event
+	if cursorEventId != nil {
+		// Get events after the cursor
+		afterCursorEvents := lo.DropWhile(feedEvents, func(event t4g.Event) bool {
+			return event.Id >= *cursorEventId
+		})
+		pageEvents = lo.Slice(afterCursorEvents, 0, limit)
+	} else {
+		page := lo.FromPtrOr(request.Params.Page, 1)
+		pageEvents = lo.Slice(feedEvents, (page-1)*limit, page*limit)
+		response.Page = &page
+	}
+
+	response.Events = lo.Map(pageEvents, func(event t4g.Event, _ int) Event { return NewEvent(event) })
+
+	// Set next cursor if there are more events
+	if len(pageEvents) != 0 {
+		lastEvent := pageEvents[len(pageEvents)-1]
+		if lastEvent.Id != feedEvents[len(feedEvents)-1].Id {
+			response.NextCursor = lo.ToPtr(encodeEventsCursor(lastEvent.Id))
+		}
+	}
+
+	if stale != nil {
+		return staleEventsResponse{response, stale.RefreshedAt}, nil
+	}
+
+	return response, nil
+}
+
+// NewEvent converts an event to its representation in the API
+func NewEvent(event t4g.Event) Event {
+	return Event{
+		Id:            event.Id,
+		Title:         event.Title,
+		Link:          event.Link,
+		Image:         event.Image,
+		Location:      event.Location,
+		Date:          event.Date,
+		Category:      event.Category,
+		StartsAt:      lo.Ternary(event.StartsAt.IsZero(), nil, lo.ToPtr(event.StartsAt)),
+		EndsAt:        lo.Ternary(event.EndsAt.IsZero(), nil, lo.ToPtr(event.EndsAt)),
+		AllDay:        event.AllDay,
+		MultipleDates: event.MultipleDates,
+	}
+}
+
+// encodeEventsCursor encodes the id of the last event of a page as an opaque cursor
+func encodeEventsCursor(eventId int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(eventId)))
+}
+
+func decodeEventsCursor(cursor string) (int, error) {
+	eventIdBytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+
+	eventId, err := strconv.Atoi(string(eventIdBytes))
+	if err != nil || eventId < 1 {
+		return 0, errors.New("invalid cursor")
+	}
+
+	return eventId, nil
+}
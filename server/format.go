@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type headersContextKey struct{}
+
+// mediaTypeFormats maps accepted media types to the feed format they represent
+var mediaTypeFormats = map[string]T4gParamsFormat{
+	"application/xml":       Rss,
+	"application/rss+xml":   Rss,
+	"text/xml":              Rss,
+	"application/atom+xml":  Atom,
+	"application/feed+json": Json,
+	"application/json":      Json,
+	"text/calendar":         Ics,
+}
+
+// requestHeadersMiddleware is a strict middleware that adds the request headers to the context.
+// This allows handlers to access headers that are not (or cannot be) declared in the openapi spec,
+// such as the Accept header.
+func requestHeadersMiddleware(f StrictHandlerFunc, _ string) StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		ctx = context.WithValue(ctx, headersContextKey{}, r.Header)
+		return f(ctx, w, r, request)
+	}
+}
+
+// requestHeaders gets the request headers from a context, or empty headers if they are not set
+func requestHeaders(ctx context.Context) http.Header {
+	headers, ok := ctx.Value(headersContextKey{}).(http.Header)
+	if !ok {
+		return http.Header{}
+	}
+	return headers
+}
+
+// feedFormat determines the format the feed should be returned in.
+// If a format is explicitly set it is used, otherwise it is negotiated using
+// the Accept header. If no supported format is acceptable, rss is used.
+func feedFormat(format *T4gParamsFormat, acceptHeader string) T4gParamsFormat {
+	if format != nil {
+		return *format
+	}
+
+	for _, mediaType := range acceptedMediaTypes(acceptHeader) {
+		if format, ok := mediaTypeFormats[mediaType]; ok {
+			return format
+		}
+	}
+
+	return Rss
+}
+
+// acceptedMediaTypes parses an Accept header, returning the accepted
+// media types ordered by their quality value (highest first)
+func acceptedMediaTypes(acceptHeader string) []string {
+	type acceptedMediaType struct {
+		mediaType string
+		quality   float64
+	}
+
+	acceptedMediaTypes := make([]acceptedMediaType, 0, strings.Count(acceptHeader, ",")+1)
+	for _, value := range strings.Split(acceptHeader, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if qualityValue, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(qualityValue, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		acceptedMediaTypes = append(acceptedMediaTypes, acceptedMediaType{mediaType, quality})
+	}
+
+	sort.SliceStable(acceptedMediaTypes, func(i, j int) bool {
+		return acceptedMediaTypes[i].quality > acceptedMediaTypes[j].quality
+	})
+
+	mediaTypes := make([]string, 0, len(acceptedMediaTypes))
+	for _, acceptedMediaType := range acceptedMediaTypes {
+		mediaTypes = append(mediaTypes, acceptedMediaType.mediaType)
+	}
+
+	return mediaTypes
+}
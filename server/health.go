@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+func (s *server) Healthz(_ context.Context, _ HealthzRequestObject) (HealthzResponseObject, error) {
+	return Healthz200JSONResponse{Status: "ok"}, nil
+}
+
+func (s *server) Readyz(_ context.Context, _ ReadyzRequestObject) (ReadyzResponseObject, error) {
+	scrape := s.client.LastScrape()
+
+	readiness := Readiness{
+		Ready:         scrape.Ready(s.client.Now(), s.readyGracePeriod),
+		LastScrapeAt:  lo.Ternary(scrape.LastAttemptAt.IsZero(), nil, lo.ToPtr(scrape.LastAttemptAt)),
+		LastSuccessAt: lo.Ternary(scrape.LastSuccessAt.IsZero(), nil, lo.ToPtr(scrape.LastSuccessAt)),
+	}
+	if scrape.LastError != nil {
+		readiness.Error = lo.ToPtr(scrape.LastError.Error())
+	}
+
+	if !readiness.Ready {
+		return Readyz503JSONResponse(readiness), nil
+	}
+
+	return Readyz200JSONResponse(readiness), nil
+}
+
+func (s *server) Version(_ context.Context, _ VersionRequestObject) (VersionResponseObject, error) {
+	return Version200JSONResponse(buildVersion()), nil
+}
+
+// buildVersion returns the version of the server using the build info embedded in the binary
+func buildVersion() Version {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Version{Version: "unknown", GoVersion: "unknown"}
+	}
+
+	version := Version{
+		Version:   buildInfo.Main.Version,
+		GoVersion: buildInfo.GoVersion,
+	}
+
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version.Revision = lo.ToPtr(setting.Value)
+		case "vcs.time":
+			revisionTime, err := time.Parse(time.RFC3339, setting.Value)
+			if err == nil {
+				version.RevisionTime = &revisionTime
+			}
+		case "vcs.modified":
+			version.Modified = lo.ToPtr(setting.Value == "true")
+		}
+	}
+
+	return version
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var httpRequestDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "t4g",
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests, by route, method and response status code",
+		Buckets:   prometheus.DefBuckets,
+	},
+	[]string{"route", "method", "status"},
+)
+
+// metricsMiddleware is a chi middleware that records the latency of requests.
+// Requests are labelled with their route pattern rather than their path,
+// so requests for different locations share the same route.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		wrappedWriter := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(wrappedWriter, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := wrappedWriter.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		httpRequestDuration.
+			WithLabelValues(route, r.Method, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
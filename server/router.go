@@ -5,14 +5,17 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/ahobsonsayers/t4g-feed/config"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httplog/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	oapimiddleware "github.com/oapi-codegen/nethttp-middleware"
 )
 
-func NewRouter() (http.Handler, error) {
+func NewRouter(cfg *config.Config, client *t4g.Client) (http.Handler, error) {
 	openAPISpec, err := loadOpenAPISpec()
 	if err != nil {
 		return nil, err
@@ -33,19 +36,34 @@ func NewRouter() (http.Handler, error) {
 			),
 		),
 
-		// Request validation middleware
-		oapimiddleware.OapiRequestValidatorWithOptions(
-			openAPISpec,
-			&oapimiddleware.Options{
-				SilenceServersWarning: true,
-			},
-		),
+		// Metrics middleware
+		metricsMiddleware,
 	)
 
+	// Metrics are not part of the OpenAPI spec, so are served outside of request validation
+	router.Handle("/metrics", promhttp.Handler())
+
 	// Create route handler for OpenAPI routes
-	openAPIHandler := NewStrictHandler(NewServer(), nil)
+	openAPIHandler := NewStrictHandler(
+		NewServer(cfg, client),
+		[]StrictMiddlewareFunc{requestHeadersMiddleware},
+	)
+
+	router.Group(func(router chi.Router) {
+		// Request validation middleware
+		router.Use(
+			oapimiddleware.OapiRequestValidatorWithOptions(
+				openAPISpec,
+				&oapimiddleware.Options{
+					SilenceServersWarning: true,
+				},
+			),
+		)
+
+		HandlerFromMux(openAPIHandler, router)
+	})
 
-	return HandlerFromMux(openAPIHandler, router), nil
+	return router, nil
 }
 
 func loadOpenAPISpec() (*openapi3.T, error) {
@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
@@ -22,25 +23,209 @@ import (
 	strictnethttp "github.com/oapi-codegen/runtime/strictmiddleware/nethttp"
 )
 
+// Defines values for T4gParamsFormat.
+const (
+	Atom T4gParamsFormat = "atom"
+	Ics  T4gParamsFormat = "ics"
+	Json T4gParamsFormat = "json"
+	Rss  T4gParamsFormat = "rss"
+)
+
+// Event defines model for event.
+type Event struct {
+	// AllDay Whether the event dates have no times
+	AllDay   bool   `json:"allDay"`
+	Category string `json:"category"`
+
+	// Date Date of the event as shown on Tickets For Good
+	Date string `json:"date"`
+
+	// EndsAt End of the event, if the date could be parsed and has an end
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+	Id       int        `json:"id"`
+	Image    string     `json:"image"`
+	Link     string     `json:"link"`
+	Location string     `json:"location"`
+
+	// MultipleDates Whether the event runs on multiple dates
+	MultipleDates bool `json:"multipleDates"`
+
+	// StartsAt Start of the event, if the date could be parsed
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	Title    string     `json:"title"`
+}
+
+// Events defines model for events.
+type Events struct {
+	// CachedTotal Number of events of the location cached by the server, which are the events that are paginated.
+	// Only the events on the event pages fetched from Tickets For Good are cached (see `-event-pages`),
+	// so there may be more matching events on Tickets For Good.
+	CachedTotal int     `json:"cachedTotal"`
+	Events      []Event `json:"events"`
+	Limit       int     `json:"limit"`
+
+	// NextCursor Cursor to get the next events. Not set if there are no more events.
+	NextCursor *string `json:"nextCursor,omitempty"`
+
+	// Page Page of events, if paginating by page
+	Page *int `json:"page,omitempty"`
+}
+
+// Health defines model for health.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// Readiness defines model for readiness.
+type Readiness struct {
+	// Error Error of the last scrape. Not set if it succeeded.
+	Error *string `json:"error,omitempty"`
+
+	// LastScrapeAt Time of the last scrape. Not set if there has not been a scrape.
+	LastScrapeAt *time.Time `json:"lastScrapeAt,omitempty"`
+
+	// LastSuccessAt Time of the last successful scrape. Not set if no scrape has succeeded.
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	Ready         bool       `json:"ready"`
+}
+
+// Version defines model for version.
+type Version struct {
+	// GoVersion Version of Go the server was built with
+	GoVersion string `json:"goVersion"`
+
+	// Modified Whether the server was built with uncommitted changes
+	Modified *bool `json:"modified,omitempty"`
+
+	// Revision VCS revision the server was built from
+	Revision *string `json:"revision,omitempty"`
+
+	// RevisionTime Time of the VCS revision
+	RevisionTime *time.Time `json:"revisionTime,omitempty"`
+
+	// Version Version of the server module
+	Version string `json:"version"`
+}
+
 // Error defines model for error.
 type Error struct {
 	Error string `json:"error"`
 }
 
+// Unavailable defines model for unavailable.
+type Unavailable struct {
+	Error string `json:"error"`
+}
+
+// EventsParams defines parameters for Events.
+type EventsParams struct {
+	// Portal Tickets For Good portal to get events from e.g. `nhs`.
+	// Must be one of the portals allowed by the server. If not set, the default portal is used.
+	Portal *string `form:"portal,omitempty" json:"portal,omitempty"`
+
+	// Location Location to get events for
+	Location *string `form:"location,omitempty" json:"location,omitempty"`
+
+	// Radius Search radius around the location in miles
+	Radius *int `form:"radius,omitempty" json:"radius,omitempty"`
+
+	// Page Page of events to get. Ignored if cursor is set.
+	Page *int `form:"page,omitempty" json:"page,omitempty"`
+
+	// Limit Maximum number of events to get
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor Cursor to get the events after, as returned in `nextCursor`
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// T4gParams defines parameters for T4g.
+type T4gParams struct {
+	// Portal Tickets For Good portal to get events from e.g. `nhs`.
+	// Must be one of the portals allowed by the server. If not set, the default portal is used.
+	Portal *string `form:"portal,omitempty" json:"portal,omitempty"`
+
+	// Radius Search radius around the location in miles
+	Radius *int `form:"radius,omitempty" json:"radius,omitempty"`
+
+	// Category Only include events in these categories (case-insensitive).
+	// Can be repeated to include multiple categories.
+	Category *[]string `form:"category,omitempty" json:"category,omitempty"`
+
+	// ExcludeCategory Exclude events in these categories (case-insensitive).
+	// Can be repeated to exclude multiple categories.
+	ExcludeCategory *[]string `form:"excludeCategory,omitempty" json:"excludeCategory,omitempty"`
+
+	// Q Only include events whose title or location contains this text (case-insensitive)
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// TitleRegex Only include events whose title matches this regular expression.
+	// Uses RE2 syntax. Prefix with `(?i)` for case-insensitive matching.
+	TitleRegex *string `form:"titleRegex,omitempty" json:"titleRegex,omitempty"`
+
+	// Format Format of the feed
+	Format *T4gParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// IfNoneMatch Only return the feed if its ETag does not match one of these ETags
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+
+	// IfModifiedSince Only return the feed if it has been modified since this time
+	IfModifiedSince *string `json:"If-Modified-Since,omitempty"`
+}
+
+// T4gParamsFormat defines parameters for T4g.
+type T4gParamsFormat string
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
-	// Get Tickets for Good Events RSS Feed
+	// Get Tickets for Good Events
+	// (GET /api/v1/events)
+	Events(w http.ResponseWriter, r *http.Request, params EventsParams)
+	// Health Check
+	// (GET /healthz)
+	Healthz(w http.ResponseWriter, r *http.Request)
+	// Readiness Check
+	// (GET /readyz)
+	Readyz(w http.ResponseWriter, r *http.Request)
+	// Version
+	// (GET /version)
+	Version(w http.ResponseWriter, r *http.Request)
+	// Get Tickets for Good Events Feed
 	// (GET /{location})
-	T4g(w http.ResponseWriter, r *http.Request, location string)
+	T4g(w http.ResponseWriter, r *http.Request, location string, params T4gParams)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
 
 type Unimplemented struct{}
 
-// Get Tickets for Good Events RSS Feed
+// Get Tickets for Good Events
+// (GET /api/v1/events)
+func (_ Unimplemented) Events(w http.ResponseWriter, r *http.Request, params EventsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Health Check
+// (GET /healthz)
+func (_ Unimplemented) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Readiness Check
+// (GET /readyz)
+func (_ Unimplemented) Readyz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Version
+// (GET /version)
+func (_ Unimplemented) Version(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get Tickets for Good Events Feed
 // (GET /{location})
-func (_ Unimplemented) T4g(w http.ResponseWriter, r *http.Request, location string) {
+func (_ Unimplemented) T4g(w http.ResponseWriter, r *http.Request, location string, params T4gParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -53,6 +238,119 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// Events operation middleware
+func (siw *ServerInterfaceWrapper) Events(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params EventsParams
+
+	// ------------- Optional query parameter "portal" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "portal", r.URL.Query(), &params.Portal)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "portal", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "location" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "location", r.URL.Query(), &params.Location)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "location", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "radius" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "radius", r.URL.Query(), &params.Radius)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "radius", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", r.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "page", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Events(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Healthz operation middleware
+func (siw *ServerInterfaceWrapper) Healthz(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Healthz(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Readyz operation middleware
+func (siw *ServerInterfaceWrapper) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Readyz(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// Version operation middleware
+func (siw *ServerInterfaceWrapper) Version(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Version(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // T4g operation middleware
 func (siw *ServerInterfaceWrapper) T4g(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -68,8 +366,107 @@ func (siw *ServerInterfaceWrapper) T4g(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params T4gParams
+
+	// ------------- Optional query parameter "portal" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "portal", r.URL.Query(), &params.Portal)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "portal", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "radius" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "radius", r.URL.Query(), &params.Radius)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "radius", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "category" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "category", r.URL.Query(), &params.Category)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "category", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "excludeCategory" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "excludeCategory", r.URL.Query(), &params.ExcludeCategory)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "excludeCategory", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "titleRegex" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "titleRegex", r.URL.Query(), &params.TitleRegex)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "titleRegex", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-None-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-None-Match", Err: err})
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	// ------------- Optional header parameter "If-Modified-Since" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Modified-Since")]; found {
+		var IfModifiedSince string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Modified-Since", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Modified-Since", valueList[0], &IfModifiedSince, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Modified-Since", Err: err})
+			return
+		}
+
+		params.IfModifiedSince = &IfModifiedSince
+
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.T4g(w, r, location)
+		siw.Handler.T4g(w, r, location, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -192,6 +589,18 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/v1/events", wrapper.Events)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/healthz", wrapper.Healthz)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/readyz", wrapper.Readyz)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/version", wrapper.Version)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/{location}", wrapper.T4g)
 	})
@@ -203,16 +612,175 @@ type ErrorJSONResponse struct {
 	Error string `json:"error"`
 }
 
+type UnavailableResponseHeaders struct {
+	RetryAfter int
+}
+type UnavailableJSONResponse struct {
+	Body struct {
+		Error string `json:"error"`
+	}
+
+	Headers UnavailableResponseHeaders
+}
+
+type EventsRequestObject struct {
+	Params EventsParams
+}
+
+type EventsResponseObject interface {
+	VisitEventsResponse(w http.ResponseWriter) error
+}
+
+type Events200JSONResponse Events
+
+func (response Events200JSONResponse) VisitEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Events400JSONResponse struct{ ErrorJSONResponse }
+
+func (response Events400JSONResponse) VisitEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Events503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response Events503JSONResponse) VisitEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type HealthzRequestObject struct {
+}
+
+type HealthzResponseObject interface {
+	VisitHealthzResponse(w http.ResponseWriter) error
+}
+
+type Healthz200JSONResponse Health
+
+func (response Healthz200JSONResponse) VisitHealthzResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReadyzRequestObject struct {
+}
+
+type ReadyzResponseObject interface {
+	VisitReadyzResponse(w http.ResponseWriter) error
+}
+
+type Readyz200JSONResponse Readiness
+
+func (response Readyz200JSONResponse) VisitReadyzResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Readyz503JSONResponse Readiness
+
+func (response Readyz503JSONResponse) VisitReadyzResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type VersionRequestObject struct {
+}
+
+type VersionResponseObject interface {
+	VisitVersionResponse(w http.ResponseWriter) error
+}
+
+type Version200JSONResponse Version
+
+func (response Version200JSONResponse) VisitVersionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type T4gRequestObject struct {
 	Location string `json:"location,omitempty"`
+	Params   T4gParams
 }
 
 type T4gResponseObject interface {
 	VisitT4gResponse(w http.ResponseWriter) error
 }
 
+type T4g200ResponseHeaders struct {
+	CacheControl string
+	ETag         string
+	LastModified string
+	Vary         string
+	Warning      string
+	XT4GStale    string
+}
+
+type T4g200ApplicationatomXmlResponse struct {
+	Body          io.Reader
+	Headers       T4g200ResponseHeaders
+	ContentLength int64
+}
+
+func (response T4g200ApplicationatomXmlResponse) VisitT4gResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/atom+xml")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
+	w.Header().Set("Warning", fmt.Sprint(response.Headers.Warning))
+	w.Header().Set("X-T4G-Stale", fmt.Sprint(response.Headers.XT4GStale))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type T4g200ApplicationFeedPlusJSONResponse struct {
+	Body    json.RawMessage
+	Headers T4g200ResponseHeaders
+}
+
+func (response T4g200ApplicationFeedPlusJSONResponse) VisitT4gResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/feed+json")
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
+	w.Header().Set("Warning", fmt.Sprint(response.Headers.Warning))
+	w.Header().Set("X-T4G-Stale", fmt.Sprint(response.Headers.XT4GStale))
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type T4g200ApplicationxmlResponse struct {
 	Body          io.Reader
+	Headers       T4g200ResponseHeaders
 	ContentLength int64
 }
 
@@ -221,6 +789,38 @@ func (response T4g200ApplicationxmlResponse) VisitT4gResponse(w http.ResponseWri
 	if response.ContentLength != 0 {
 		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
 	}
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
+	w.Header().Set("Warning", fmt.Sprint(response.Headers.Warning))
+	w.Header().Set("X-T4G-Stale", fmt.Sprint(response.Headers.XT4GStale))
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type T4g200TextcalendarResponse struct {
+	Body          io.Reader
+	Headers       T4g200ResponseHeaders
+	ContentLength int64
+}
+
+func (response T4g200TextcalendarResponse) VisitT4gResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/calendar")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
+	w.Header().Set("Warning", fmt.Sprint(response.Headers.Warning))
+	w.Header().Set("X-T4G-Stale", fmt.Sprint(response.Headers.XT4GStale))
 	w.WriteHeader(200)
 
 	if closer, ok := response.Body.(io.ReadCloser); ok {
@@ -230,6 +830,30 @@ func (response T4g200ApplicationxmlResponse) VisitT4gResponse(w http.ResponseWri
 	return err
 }
 
+type T4g304ResponseHeaders struct {
+	CacheControl string
+	ETag         string
+	LastModified string
+	Vary         string
+	Warning      string
+	XT4GStale    string
+}
+
+type T4g304Response struct {
+	Headers T4g304ResponseHeaders
+}
+
+func (response T4g304Response) VisitT4gResponse(w http.ResponseWriter) error {
+	w.Header().Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	w.Header().Set("ETag", fmt.Sprint(response.Headers.ETag))
+	w.Header().Set("Last-Modified", fmt.Sprint(response.Headers.LastModified))
+	w.Header().Set("Vary", fmt.Sprint(response.Headers.Vary))
+	w.Header().Set("Warning", fmt.Sprint(response.Headers.Warning))
+	w.Header().Set("X-T4G-Stale", fmt.Sprint(response.Headers.XT4GStale))
+	w.WriteHeader(304)
+	return nil
+}
+
 type T4g400JSONResponse struct{ ErrorJSONResponse }
 
 func (response T4g400JSONResponse) VisitT4gResponse(w http.ResponseWriter) error {
@@ -239,9 +863,42 @@ func (response T4g400JSONResponse) VisitT4gResponse(w http.ResponseWriter) error
 	return json.NewEncoder(w).Encode(response)
 }
 
+type T4g500JSONResponse struct {
+	Error string `json:"error"`
+}
+
+func (response T4g500JSONResponse) VisitT4gResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type T4g503JSONResponse struct{ UnavailableJSONResponse }
+
+func (response T4g503JSONResponse) VisitT4gResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 // StrictServerInterface represents all server handlers.
 type StrictServerInterface interface {
-	// Get Tickets for Good Events RSS Feed
+	// Get Tickets for Good Events
+	// (GET /api/v1/events)
+	Events(ctx context.Context, request EventsRequestObject) (EventsResponseObject, error)
+	// Health Check
+	// (GET /healthz)
+	Healthz(ctx context.Context, request HealthzRequestObject) (HealthzResponseObject, error)
+	// Readiness Check
+	// (GET /readyz)
+	Readyz(ctx context.Context, request ReadyzRequestObject) (ReadyzResponseObject, error)
+	// Version
+	// (GET /version)
+	Version(ctx context.Context, request VersionRequestObject) (VersionResponseObject, error)
+	// Get Tickets for Good Events Feed
 	// (GET /{location})
 	T4g(ctx context.Context, request T4gRequestObject) (T4gResponseObject, error)
 }
@@ -275,11 +932,110 @@ type strictHandler struct {
 	options     StrictHTTPServerOptions
 }
 
+// Events operation middleware
+func (sh *strictHandler) Events(w http.ResponseWriter, r *http.Request, params EventsParams) {
+	var request EventsRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Events(ctx, request.(EventsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Events")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(EventsResponseObject); ok {
+		if err := validResponse.VisitEventsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Healthz operation middleware
+func (sh *strictHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	var request HealthzRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Healthz(ctx, request.(HealthzRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Healthz")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(HealthzResponseObject); ok {
+		if err := validResponse.VisitHealthzResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Readyz operation middleware
+func (sh *strictHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	var request ReadyzRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Readyz(ctx, request.(ReadyzRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Readyz")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ReadyzResponseObject); ok {
+		if err := validResponse.VisitReadyzResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Version operation middleware
+func (sh *strictHandler) Version(w http.ResponseWriter, r *http.Request) {
+	var request VersionRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Version(ctx, request.(VersionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Version")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(VersionResponseObject); ok {
+		if err := validResponse.VisitVersionResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // T4g operation middleware
-func (sh *strictHandler) T4g(w http.ResponseWriter, r *http.Request, location string) {
+func (sh *strictHandler) T4g(w http.ResponseWriter, r *http.Request, location string, params T4gParams) {
 	var request T4gRequestObject
 
 	request.Location = location
+	request.Params = params
 
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
 		return sh.ssi.T4g(ctx, request.(T4gRequestObject))
@@ -304,12 +1060,51 @@ func (sh *strictHandler) T4g(w http.ResponseWriter, r *http.Request, location st
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/3yRT2/CMAzFv0rl7RjRbuOU2w6AuE1jN8Qha10Ia+PMCWioynefHOj+MGk3x7Xf8/t1",
-	"gJp6Tw5dDKAHYAyeXMD8QGZiKWpyEV2U0njf2dpES67cB3LSC/UOeyOVZ/LI0V7tx5NH0BAiW7eFlBQw",
-	"vh8sYwN6fRnbqHGMXvdYR0gy12Co2XqxAw2zPJk/WNdSlraxk6UXW79hDMWcuFgQNcUcsSken5ag4Igc",
-	"zgJ3k2pSQVJAHp3xFjQ85JYCb+IuX10OHZ0DJnluMeeWXLm5bMRtus0rbHqMyAH0egArDiIDCpzp5apR",
-	"CdQPSNc0Nuo39vuq+gf6R9+BHv6yeV6tihaxkXTTs8ItYwsabsrvf1x+OZV4gakgHPre8Ak0LDAWI8l2",
-	"JDk7ymYhBvNskFL6DAAA//9uNFc7OAIAAA==",
+	"H4sIAAAAAAAC/+xabY/bNvL/KgP9/y9aVLY3D32zbw65bZLm0KTF7l56QF3AY2lksZFIlaTW9i32ux+G",
+	"FPVgyfbuNT0Eh3tnSxzOI2d+M9R9lKiyUpKkNdHlfZQTpqTdzytMcppdKWm1KvhBSibRorJCyegy+l5t",
+	"oVByAzYnyIhSSFDCmiBhuhQypWFNmdIEwkKJe36nKdNkckqjODJJTiXyxnZfUXQZGauF3EQPD3H0+hY3",
+	"EyzR5KCyHkclLUl7ZrMf0NjZe5WKTFA63vVWlDTaErZooEBjoQyEp5lck9X72avMkh6z+FCXa9IsuqFE",
+	"ydSAVbBFYYOFNFOLxpiafq/JTGolpKUNacfxI+r9mNW1J4bGkZ1iKVXEnJWcwy0/VLpEOzSnd6CkjbIC",
+	"LaVLWZsg1epVklBlV83OMRjlXW2grI0FY1kRwiQPWxuqUKOlYj9fyjPm+xm15N8jfW7IsrFWz55dwAyW",
+	"0TWZSklDIAzcWCxoGa1AZHArkk9kDbxRGt4qxY6sixSksj7uXFA6oVkZ9uxSZmR9qLLywoAhfUfpHD4o",
+	"Ft6CsjnprTB0Xv5/zG5fvp05gY5EGILht55ZG13tgZjDj7LYO7ZbYXMnpSdYNbZZzU/K8BBHurGNO76k",
+	"tXKhGA7J5X2EVVWIBFmsxW+GZbvv7VhpVZG24oB+rCwHqNB8ln5plv0ah2Vq/Rsl1ssztMJrt/IhjmqJ",
+	"dygKXBf0Jcn3qAgClCn7RrsAlAoqTXdC1abYwyCcrPLhFMX9nHqQJf5fUxZdRv+36JLwolm96C99cOJ6",
+	"S3jl7xqLDW2CRfEdTiSFn3NimV1QOVJI0ZKBHO+IlbCiJBO1NlorVRBK9lWCljbKJ5oDQ8cRbzJm9h1a",
+	"CmnFM0MDJldbCUqOzmkUj/clmZpXdrzza5kONo5B+H8sSOOuNUGF2jSeytEASiDJfHxaii6d3DPWeYq5",
+	"SKfybRyJEjc0aYZCyE/TL5SP5cmXZV1YURXE5jKPcZmuJWdvCITeh5NeMxa1nTThDb95vBEfbTUrbEHn",
+	"T6NIo7C2sVswbM9aTWD1gi8OkX1otvG5jv3ZMOPD4XHJrbJYnKrQnjyYKAgVUM167x67o61j2OYiyQE1",
+	"dfbkkovWPatwIyTX0flSuuzeW6Rk948Xkunyh1bluJ7xho0QXxkiWM0c7czRrr6Ol9LXNk0BbJXK/bZJ",
+	"zjW843u4ta9u44jvDCksleZYwmrS0sItjx7anVBr3PvzUQo7fagk7exVrY2aAE3+OSfSDVlnLF7d6NEV",
+	"aR+9mpyBpPJaN4umIrVqjvGQ2U+4oc777kw03mPbrfdQ+SCdwGGDauPIo6BzPAi6qWDNCQubj4PVWLS1",
+	"+0U7LKvCUX0a63PAvyGb4qQJUyHJmBOldKJgtwcBjQWTaKxoYHthwdRJQpRSOmlvJrxxdK/sEWh0hoV3",
+	"L6dyX4tJAoZ1j05QTgwW1JjHyeHXZnUxJZJUzVMn1sAAjxOHvdEvqm3yPnCoXzflzzvSpqktQ29u1Mfu",
+	"1VDL5gUr+lb1EpnDo+taFB58TglcHu2f+pVqcjuoZaLKUlhLKSQ5ys2RssVQ6ojgVzcQ3k4z4qQ5bWhP",
+	"xQ4+7fY+j0f78e4Rpu7JW6q0LujsMQ67xj1nTkNYITPlwsjX3zGIfUOUwquf3kU9WaNn84v5BYuvKpJY",
+	"iegyeuEecXq0uQujBVZicfds0VWBDU0cnLdk24KSBaaexv3HroSigb/d/PghBklbMhYyoY2dL+VSvvbr",
+	"m/6zLZtAwkVWk35B+iLte9IVP1rFwGOG/VJWaLpWtSsrq+CAgNMhNEkszirxi+ZL6ZcbV0WM5d4EtjlJ",
+	"ljWow68wTZs2El3bzoFNMu1KPAVVxnBhiAlicFW8VyBWzhbvpnpZlIeN7ETf0Ym5lI5jOiyOKPdxr7nE",
+	"MnSYYVaAxvUuxgMCzinOce9SLgahsnFPX5J1Dc0v9+d6qEppi0Uo4iEuGODQfDOHlcwNq/2eJwhrAiXb",
+	"4+gpDWBRqO2hKefwLnPVwJCN3fOUMqwLGxgKA7WhBtoIFu33mhySlMiJIPLrTnf1h9r9ECL5QB2ljzDp",
+	"QdonsLkh1EkOGlNRsztV7XvO7iQJCaUoyBxh6ykHTBvzRJcvLuKoxJ0o6zK6fHbB/4Rs/k3Bm9NAqbHE",
+	"HN5tpNI+4PyZ8vMUOz9mfg+nJgR89lSJ3nttQnY4lO2YaxqANiHB8z9oojFwDSczs6RjPmaabK0l20sO",
+	"stURYb1JT0bRrwfjn+cXF08arpxF9mZyptO8iaOXFxfHtmnlWlAYAX178eL86v6ciFmbuizdzHO66ARZ",
+	"HuJo4WH1P49Wrauckk/91CwM99duBHmY+b5v9voTDezFnTLwzUi8oSW8cOD08ao7xPhEzZmknVj5GhDD",
+	"Gg2loU9VtU3UECIvZQOBVTbVUi7l7QQTMYL6HXp2tXwS7y9lAPywd9lmvEuGouAthpoZK4qiY41LecjU",
+	"FUThlUyUzMSm1pTCRmNCUJEWoT8eBsW1t/KfGBNdv3Y6LFiQ3pH6T/Jm9zT8B0F5Hcj7cdlDykeBJEN5",
+	"zokee4/g88gLH1uc/Ke5IYg9YYi/Hkp7YIaPLWkcLe5D8X44aQH002OVjfPbJKhuD5oj85K0F3G5MiSh",
+	"d4Xj36/AlRdokdzcoU6bCxO82sKqZkdherdCcPxSaCkzLAp+u0ZOMwqub25aGVciMauwpS+BbjwrrrAg",
+	"maKGJPzoTyhNmLN5vZbS1Gu229qP2YXsyLCqzBzCdr6jwMIoD53dzZiHkpimLCXKpVzNnVi0syRNg+4G",
+	"aMsj1UWhZKqkW/wUoI5HrwiEGeN0YUBJmvurLbesmdJjew90CafuwpbSu8KNv1HCqnczFdzE7g1a2sHt",
+	"5/heKl5KtxOj8HDf11YKm1PJvilY3zssROrmZSFBT1ySDC/d2DrfXrzweaxBRK5BQVj17j+C3FOJ+Pbl",
+	"ZtyUOAzFnewfheL/3f3MF91ouG5ayKSo0xZA+zptCJqbAUEGvkrQ0ExIw6fXijv6mrt5nwE1VYQ2pAm/",
+	"VXt90u1x3Kq9G4hOxXYaPr4FGUy+xzq93n02dWj3b6jTEF19Xq2mPLXl2gNuKgVK965RlLQopPHVxvJA",
+	"f6zxEel/H8hb4u4HkhubN9F1NtrPieluSqiRTNOmLlAD7SpNxvhK+3dDBq5fPwezlxZ3c/hJUyZ2PmWt",
+	"vvqL+NpVNzhUqL2EOe4ZJ8I1bWh3TMnnj1LyzeirjiMMm/FmnxlJPpy/RNrwkUbrBqoOLcWRSPq3CmeM",
+	"7HN5V1XcNYEB/qgHUkUeYjib9BKkIbegTTU+5XcCv8tmH5Sk2Xume1qeOy6U6zLcjUKYb4MRMqEmPP3Q",
+	"95g44ZOi2Q2TfMbunE3/za7ka8qHePCGJf9mDGB3s42aNTz57fwat+/JGNz4zrm/RbsvH75FQE78aARw",
+	"3/jwOfVN2KkvGIaLe191nSJya6Y+2jpFNFzc+zbqFJFbM/z06NTysGz8sc8pqv5S10K8uHg5Rv5s6OEF",
+	"V++rs/+Z/zOa/6ljqi9jqOVucViBh38NAPgkYA+wKQAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
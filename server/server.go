@@ -2,32 +2,173 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ahobsonsayers/t4g-feed/config"
 	"github.com/ahobsonsayers/t4g-feed/t4g"
 	"github.com/samber/lo"
 )
 
-type server struct{}
+const icsExtension = ".ics"
 
-func NewServer() StrictServerInterface { return &server{} }
+type server struct {
+	client           *t4g.Client
+	debounceTime     time.Duration
+	readyGracePeriod time.Duration
+}
+
+func NewServer(cfg *config.Config, client *t4g.Client) StrictServerInterface {
+	return &server{
+		client:           client,
+		debounceTime:     cfg.DebounceTime,
+		readyGracePeriod: cfg.ReadyGracePeriod,
+	}
+}
+
+func (s *server) T4g(ctx context.Context, request T4gRequestObject) (T4gResponseObject, error) {
+	var titleRegex *regexp.Regexp
+	if request.Params.TitleRegex != nil {
+		var err error
+		titleRegex, err = regexp.Compile(*request.Params.TitleRegex)
+		if err != nil {
+			return T4g400JSONResponse{ErrorJSONResponse{Error: fmt.Sprintf("invalid title regex: %s", err)}}, nil
+		}
+	}
 
-func (*server) T4g(ctx context.Context, request T4gRequestObject) (T4gResponseObject, error) {
-	feed, err := t4g.FetchFeed(ctx, &request.Location, lo.ToPtr(5*time.Minute))
+	portal, err := s.lookupPortal(request.Params.Portal)
 	if err != nil {
 		return T4g400JSONResponse{ErrorJSONResponse{Error: err.Error()}}, nil
 	}
 
-	rssFeed, err := feed.ToRss()
+	// Locations with an ics extension are requests for a calendar
+	location, isCalendar := strings.CutSuffix(request.Location, icsExtension)
+	if isCalendar {
+		request.Params.Format = lo.ToPtr(Ics)
+	}
+
+	feedInput := t4g.FeedInput{
+		Portal:   portal,
+		Location: &location,
+		Radius:   request.Params.Radius,
+		Filter: t4g.EventFilter{
+			Categories:        lo.FromPtr(request.Params.Category),
+			ExcludeCategories: lo.FromPtr(request.Params.ExcludeCategory),
+			Query:             request.Params.Q,
+			TitleRegex:        titleRegex,
+		},
+	}
+
+	feed, stale, err := s.fetchFeed(ctx, feedInput)
 	if err != nil {
-		return T4g400JSONResponse{ErrorJSONResponse{Error: err.Error()}}, nil
+		return T4g503JSONResponse{unavailableResponse(err)}, nil
+	}
+
+	format := feedFormat(request.Params.Format, requestHeaders(ctx).Get("Accept"))
+	feedContent, err := feed.Render(string(format))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to render feed", "format", format, "error", err)
+		return T4g500JSONResponse{Error: "failed to render feed"}, nil
+	}
+
+	headers := feedCacheHeaders(feedContent, feed.UpdatedAt(), feed.RefreshedAt(), s.client.Now(), s.debounceTime)
+	if stale != nil {
+		headers.CacheControl = staleCacheControl
+		headers.Warning = staleWarning
+		headers.XT4GStale = staleHeaderValue(stale.RefreshedAt)
+	}
+
+	var response T4gResponseObject
+	if isNotModified(request.Params.IfNoneMatch, request.Params.IfModifiedSince, headers) {
+		response = T4g304Response{Headers: T4g304ResponseHeaders(headers)}
+	} else {
+		response = feedResponse(format, feedContent, headers)
+	}
+
+	return omitEmptyHeadersT4gResponse{response}, nil
+}
+
+// feedResponse returns the response of rendered feed content in a format
+func feedResponse(format T4gParamsFormat, feedContent string, headers T4g200ResponseHeaders) T4gResponseObject {
+	switch format {
+	case Atom:
+		atomFeedReader := strings.NewReader(feedContent)
+
+		return T4g200ApplicationatomXmlResponse{
+			Body:          atomFeedReader,
+			Headers:       headers,
+			ContentLength: int64(atomFeedReader.Len()),
+		}
+
+	case Json:
+		return T4g200ApplicationFeedPlusJSONResponse{
+			Body:    json.RawMessage(feedContent),
+			Headers: headers,
+		}
+
+	case Ics:
+		calendarReader := strings.NewReader(feedContent)
+
+		return T4g200TextcalendarResponse{
+			Body:          calendarReader,
+			Headers:       headers,
+			ContentLength: int64(calendarReader.Len()),
+		}
+
+	default:
+		rssFeedReader := strings.NewReader(feedContent)
+
+		return T4g200ApplicationxmlResponse{
+			Body:          rssFeedReader,
+			Headers:       headers,
+			ContentLength: int64(rssFeedReader.Len()),
+		}
+	}
+}
+
+// fetchFeed fetches a feed. If only some event pages could not be fetched,
+// this is logged and the feed is returned as it has still been updated.
+// If the feed could not be refreshed but has been fetched before,
+// the stale feed is returned along with its stale error.
+func (s *server) fetchFeed(
+	ctx context.Context,
+	input t4g.FeedInput,
+) (feed *t4g.Feed, stale *t4g.StaleFeedError, err error) {
+	feed, err = s.client.FetchFeed(ctx, input, lo.ToPtr(s.debounceTime))
+
+	var partialErr *t4g.PartialError
+	if errors.As(err, &partialErr) {
+		slog.WarnContext(
+			ctx, "Some event pages could not be fetched, serving partial feed",
+			"feed", input.Key(), "failedPages", partialErr.FailedPages(), "error", err,
+		)
+		return feed, nil, nil
+	}
+
+	if errors.As(err, &stale) {
+		slog.WarnContext(ctx, "Failed to refresh feed, serving stale feed", "feed", input.Key(), "error", err)
+		return feed, stale, nil
+	}
+
+	return feed, nil, err
+}
+
+// lookupPortal looks up the portal chosen by a request. If no portal is chosen, nil is returned
+// so the default portal is used.
+func (s *server) lookupPortal(name *string) (*t4g.Portal, error) {
+	if name == nil {
+		return nil, nil
 	}
 
-	rssFeedReader := strings.NewReader(rssFeed)
+	portal, err := s.client.LookupPortal(*name)
+	if err != nil {
+		return nil, err
+	}
 
-	return T4g200ApplicationxmlResponse{
-		Body:          rssFeedReader,
-		ContentLength: int64(rssFeedReader.Len()),
-	}, nil
+	return &portal, nil
 }
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/config"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter creates a router serving feeds from a fake Tickets For Good site
+func newTestRouter(t *testing.T, upstream *t4gtest.Server) http.Handler {
+	cfg := config.Default()
+	cfg.DebounceTime = 0
+	router, err := NewRouter(&cfg, upstream.Client(t))
+	require.NoError(t, err)
+	return router
+}
+
+func serve(router http.Handler, target string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
+
+func TestServeFeed(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	router := newTestRouter(t, upstream)
+
+	// RSS is served by default
+	response := serve(router, "/london", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Header().Get("Content-Type"), "application/xml")
+	require.Contains(t, response.Body.String(), "<title>Hamilton</title>")
+	require.Contains(t, response.Body.String(), upstream.URL+"/events/"+strconv.Itoa(t4gtest.FirstEventId))
+	require.NotEmpty(t, response.Header().Get("ETag"))
+
+	// Unchanged feeds are not served again
+	response = serve(router, "/london", map[string]string{"If-None-Match": response.Header().Get("ETag")})
+	require.Equal(t, http.StatusNotModified, response.Code)
+
+	// Formats can be chosen with the format query parameter
+	response = serve(router, "/london?format=json", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Header().Get("Content-Type"), "application/feed+json")
+
+	// The rendered feed is served as is, so fields keep their order
+	require.True(t, strings.HasPrefix(response.Body.String(), `{"version":`), response.Body.String())
+
+	var jsonFeed struct {
+		Items []struct {
+			Id    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	err := json.Unmarshal(response.Body.Bytes(), &jsonFeed)
+	require.NoError(t, err)
+	require.NotEmpty(t, jsonFeed.Items)
+	require.Equal(t, strconv.Itoa(t4gtest.FirstEventId), jsonFeed.Items[0].Id)
+	require.Equal(t, "Hamilton", jsonFeed.Items[0].Title)
+
+	// Calendars can be requested with an extension
+	response = serve(router, "/london.ics", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Header().Get("Content-Type"), "text/calendar")
+	require.Contains(t, response.Body.String(), "SUMMARY:Hamilton")
+	require.Contains(t, response.Body.String(), "DTSTART:20240120T193000Z")
+}
+
+func TestServeFeedVary(t *testing.T) {
+	router := newTestRouter(t, t4gtest.NewServer(t))
+
+	// Feeds in different formats are served from the same url, so caches must store each format separately
+	response := serve(router, "/london", map[string]string{"Accept": "application/atom+xml"})
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Header().Get("Content-Type"), "application/atom+xml")
+	require.Equal(t, "Accept", response.Header().Get("Vary"))
+
+	response = serve(router, "/london", map[string]string{
+		"Accept":        "application/atom+xml",
+		"If-None-Match": response.Header().Get("ETag"),
+	})
+	require.Equal(t, http.StatusNotModified, response.Code)
+	require.Equal(t, "Accept", response.Header().Get("Vary"))
+}
+
+func TestServeFeedPortal(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	router := newTestRouter(t, upstream)
+
+	response := serve(router, "/london?portal="+t4gtest.MirrorPortal, nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Body.String(), "<title>T4G Feed: London [mirror]</title>")
+	require.Contains(t, response.Body.String(), upstream.URL+"/mirror/events/"+strconv.Itoa(t4gtest.FirstEventId))
+
+	// Only allowed portals can be used
+	response = serve(router, "/london?portal=other", nil)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+	require.Contains(t, response.Body.String(), "unknown portal")
+
+	response = serve(router, "/api/v1/events?portal=other", nil)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestServeFeedRadius(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	cfg := config.Default()
+	cfg.DebounceTime = time.Hour
+	router, err := NewRouter(&cfg, upstream.Client(t))
+	require.NoError(t, err)
+
+	// Radii must be between 1 and 100 miles
+	for _, radius := range []string{"0", "-5", "101", "far"} {
+		response := serve(router, "/london?radius="+radius, nil)
+		require.Equal(t, http.StatusBadRequest, response.Code, radius)
+		response = serve(router, "/api/v1/events?location=london&radius="+radius, nil)
+		require.Equal(t, http.StatusBadRequest, response.Code, radius)
+	}
+	require.Zero(t, upstream.PageRequests("london", 1))
+
+	// Feeds of different radii are cached separately
+	response := serve(router, "/london?radius=10", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Body.String(), "range=10")
+	response = serve(router, "/london?radius=10", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, 1, upstream.PageRequests("london", 1))
+
+	response = serve(router, "/london?radius=20", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Body.String(), "range=20")
+	require.Equal(t, 2, upstream.PageRequests("london", 1))
+}
+
+func TestServeEvents(t *testing.T) {
+	router := newTestRouter(t, t4gtest.NewServer(t))
+
+	// Get the first page of events, and then the events after it using the cursor
+	response := serve(router, "/api/v1/events?location=events-test&limit=5", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var events Events
+	err := json.Unmarshal(response.Body.Bytes(), &events)
+	require.NoError(t, err)
+	require.Len(t, events.Events, 5)
+	require.Equal(t, t4gtest.FirstEventId, events.Events[0].Id)
+	require.NotNil(t, events.NextCursor)
+
+	response = serve(router, "/api/v1/events?location=events-test&limit=5&cursor="+*events.NextCursor, nil)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var nextEvents Events
+	err = json.Unmarshal(response.Body.Bytes(), &nextEvents)
+	require.NoError(t, err)
+	require.Len(t, nextEvents.Events, 5)
+	require.Equal(t, events.Events[4].Id-1, nextEvents.Events[0].Id)
+	require.Equal(t, events.CachedTotal, nextEvents.CachedTotal)
+
+	// Invalid requests are rejected
+	response = serve(router, "/api/v1/events?limit=0", nil)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestServeEventsCachedTotal(t *testing.T) {
+	// Only the first of the event pages of the upstream site is fetched
+	router := newTestRouter(t, t4gtest.NewServer(t))
+
+	response := serve(router, "/api/v1/events?location=events-cached&limit=100", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// Only the cached events are counted and paginated, not every event listed upstream
+	var events Events
+	err := json.Unmarshal(response.Body.Bytes(), &events)
+	require.NoError(t, err)
+	require.Equal(t, t4gtest.Page1Events, events.CachedTotal)
+	require.Len(t, events.Events, t4gtest.Page1Events)
+	require.Nil(t, events.NextCursor)
+
+	response = serve(router, "/api/v1/events?location=events-cached&limit=5&page=3", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var lastPage Events
+	err = json.Unmarshal(response.Body.Bytes(), &lastPage)
+	require.NoError(t, err)
+	require.Equal(t, t4gtest.Page1Events, lastPage.CachedTotal)
+	require.Len(t, lastPage.Events, t4gtest.Page1Events-10)
+	require.Nil(t, lastPage.NextCursor)
+}
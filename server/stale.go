@@ -0,0 +1,54 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+)
+
+const (
+	// StaleHeader is set on responses of stale feeds, to the time the feed was last refreshed
+	StaleHeader = "X-T4G-Stale"
+
+	// staleWarning is the warning header of stale responses. See RFC 7234 section 5.5.1.
+	staleWarning = `110 - "Response is Stale"`
+)
+
+// staleCacheControl is the cache control header of stale feeds. Caches should revalidate stale feeds,
+// but can keep serving them while doing so until a refresh of the feed is retried.
+var staleCacheControl = fmt.Sprintf(
+	"public, max-age=0, stale-while-revalidate=%d",
+	int(t4g.FailedRefreshRetryInterval.Seconds()),
+)
+
+// staleEventsResponse is the response of events of a stale feed
+type staleEventsResponse struct {
+	EventsResponseObject
+	refreshedAt time.Time
+}
+
+func (response staleEventsResponse) VisitEventsResponse(w http.ResponseWriter) error {
+	setStaleHeaders(w, response.refreshedAt)
+	return response.EventsResponseObject.VisitEventsResponse(w)
+}
+
+func setStaleHeaders(w http.ResponseWriter, refreshedAt time.Time) {
+	w.Header().Set("Warning", staleWarning)
+	w.Header().Set(StaleHeader, staleHeaderValue(refreshedAt))
+}
+
+// staleHeaderValue returns the value of the stale header of a feed last refreshed at a time
+func staleHeaderValue(refreshedAt time.Time) string {
+	return refreshedAt.UTC().Format(http.TimeFormat)
+}
+
+// unavailableResponse returns the response when a feed could not be fetched and there is no stale feed to serve
+func unavailableResponse(err error) UnavailableJSONResponse {
+	response := UnavailableJSONResponse{
+		Headers: UnavailableResponseHeaders{RetryAfter: int(t4g.FailedRefreshRetryInterval.Seconds())},
+	}
+	response.Body.Error = fmt.Sprintf("failed to get events from Tickets For Good: %s", err)
+	return response
+}
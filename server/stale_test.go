@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeStaleFeed(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	router := newTestRouter(t, upstream)
+
+	// Feeds that have never been fetched are unavailable
+	upstream.SetStatus(http.StatusServiceUnavailable)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/stale-test-unavailable", http.NoBody))
+	require.Equal(t, http.StatusServiceUnavailable, response.Code)
+	require.Equal(t, "60", response.Header().Get("Retry-After"))
+
+	// Fetch the feed while upstream is up
+	upstream.SetStatus(0)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/stale-test", http.NoBody))
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NotContains(t, response.Header(), "Warning")
+	require.NotContains(t, response.Header(), StaleHeader)
+	freshFeed := response.Body.String()
+	etag := response.Header().Get("ETag")
+
+	// Previously fetched feeds are served stale
+	upstream.SetStatus(http.StatusServiceUnavailable)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/stale-test", http.NoBody))
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, staleWarning, response.Header().Get("Warning"))
+	require.NotEmpty(t, response.Header().Get(StaleHeader))
+	require.Equal(t, staleCacheControl, response.Header().Get("Cache-Control"))
+	require.Equal(t, freshFeed, response.Body.String())
+
+	// Stale feeds that have not been modified are still marked as stale
+	request := httptest.NewRequest(http.MethodGet, "/stale-test", http.NoBody)
+	request.Header.Set("If-None-Match", etag)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	require.Equal(t, http.StatusNotModified, response.Code)
+	require.Equal(t, staleWarning, response.Header().Get("Warning"))
+	require.NotEmpty(t, response.Header().Get(StaleHeader))
+}
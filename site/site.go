@@ -0,0 +1,287 @@
+package site
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/utils"
+)
+
+const (
+	indexFile = "index.html"
+	opmlFile  = "feeds.opml"
+	siteTitle = "T4G Feeds"
+)
+
+// nonSlugCharsRegex matches characters that are not allowed in the file names of feeds
+var nonSlugCharsRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Options are the options of a generated site
+type Options struct {
+	OutputDir  string // Directory the site is written to
+	BaseURL    string // Url the site is hosted at, used for links in the OPML. If empty, links are relative.
+	EventPages int    // Number of event pages to get when updating each feed
+}
+
+// siteFeed is a feed written to the site
+type siteFeed struct {
+	Name      string // Name of the feed files, without an extension
+	Title     string
+	Link      string // Url of the events of the feed on Tickets For Good
+	UpdatedAt time.Time
+}
+
+// Generate generates a static site of feeds in the output directory. Each feed is updated and written as RSS,
+// Atom, JSON and ICS files named after its location, along with an index page and an OPML file of every feed.
+//
+// The history of each feed is kept in the feed store between generations, so items keep the time they were
+// created. Feeds that could not be updated are still written if they have been generated before. If some feeds
+// could not be written, the rest of the site is still generated and the errors of the feeds are returned.
+func Generate(
+	ctx context.Context, client *t4g.Client, store t4g.FeedStore, inputs []t4g.FeedInput, options Options,
+) error {
+	if options.EventPages < 1 {
+		return errors.New("event pages must be at least 1")
+	}
+	_, err := siteURL(options.BaseURL, opmlFile)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		name := feedName(input)
+		if name == "" || strings.HasPrefix(name, "-") {
+			return fmt.Errorf("location %q must contain letters or numbers", *input.Location)
+		}
+		if names[name] {
+			return fmt.Errorf("more than one feed has the name %q", name)
+		}
+		names[name] = true
+	}
+
+	err = os.MkdirAll(options.OutputDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var errs []error
+	siteFeeds := make([]siteFeed, 0, len(inputs))
+	for _, input := range inputs {
+		siteFeed, err := generateFeed(ctx, client, store, input, options)
+		if err != nil {
+			// Stop if cancelled, rather than failing every remaining feed
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, err)
+			continue
+		}
+		siteFeeds = append(siteFeeds, siteFeed)
+	}
+
+	err = writeIndex(siteFeeds, options)
+	if err != nil {
+		return err
+	}
+
+	err = writeOPML(siteFeeds, options)
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// generateFeed restores a feed from the store, updates it, saves it and writes its files
+func generateFeed(
+	ctx context.Context, client *t4g.Client, store t4g.FeedStore, input t4g.FeedInput, options Options,
+) (siteFeed, error) {
+	name := feedName(input)
+	key := client.FeedKey(input)
+
+	feed := client.NewFeed(input)
+	snapshot, err := store.LoadFeed(key)
+	if err == nil {
+		feed.Restore(snapshot)
+	} else if !errors.Is(err, t4g.ErrFeedNotFound) {
+		return siteFeed{}, fmt.Errorf("failed to load feed %s: %w", name, err)
+	}
+
+	err = feed.Update(ctx, options.EventPages)
+	var partialErr *t4g.PartialError
+	switch {
+	case err == nil || errors.As(err, &partialErr):
+		if partialErr != nil {
+			slog.WarnContext(
+				ctx, "Some event pages could not be fetched, generating partial feed",
+				"feed", name, "failedPages", partialErr.FailedPages(), "error", err,
+			)
+		}
+
+		err = store.SaveFeed(key, feed.Snapshot())
+		if err != nil {
+			return siteFeed{}, fmt.Errorf("failed to save feed %s: %w", name, err)
+		}
+
+	case feed.RefreshedAt().IsZero() || ctx.Err() != nil:
+		return siteFeed{}, fmt.Errorf("failed to update feed %s: %w", name, err)
+
+	default:
+		slog.WarnContext(ctx, "Failed to update feed, generating stale feed", "feed", name, "error", err)
+	}
+
+	// Each feed is written in every format, with the format as the file extension
+	for _, format := range t4g.FeedFormats {
+		feedContent, err := feed.Render(format)
+		if err != nil {
+			return siteFeed{}, fmt.Errorf("failed to render feed %s: %w", name, err)
+		}
+
+		err = utils.WriteFile(filepath.Join(options.OutputDir, name+"."+format), []byte(feedContent), 0o644)
+		if err != nil {
+			return siteFeed{}, fmt.Errorf("failed to write feed %s: %w", name, err)
+		}
+	}
+
+	return siteFeed{
+		Name:      name,
+		Title:     feed.Title(),
+		Link:      feed.Link(),
+		UpdatedAt: feed.UpdatedAt(),
+	}, nil
+}
+
+// feedName returns the name of the files of a feed, from its location and portal
+func feedName(input t4g.FeedInput) string {
+	name := "all"
+	if input.Location != nil {
+		name = strings.Trim(nonSlugCharsRegex.ReplaceAllString(strings.ToLower(*input.Location), "-"), "-")
+	}
+
+	if input.Portal != nil && input.Portal.Name != t4g.DefaultPortalName {
+		name = fmt.Sprintf("%s-%s", name, input.Portal.Name)
+	}
+
+	return name
+}
+
+// indexTemplate is the template of the index page of the site
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Title }}</title>
+<link rel="alternate" type="text/x-opml" title="{{ .Title }}" href="{{ .OPMLFile }}">
+{{- range .Feeds }}
+<link rel="alternate" type="application/rss+xml" title="{{ .Title }}" href="{{ .Name }}.rss">
+{{- end }}
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<ul>
+{{- range .Feeds }}
+<li>
+<a href="{{ .Link }}">{{ .Title }}</a>
+(updated {{ .UpdatedAt.UTC.Format "2 Jan 2006 15:04 MST" }}):
+<a href="{{ .Name }}.rss">RSS</a>,
+<a href="{{ .Name }}.atom">Atom</a>,
+<a href="{{ .Name }}.json">JSON</a>,
+<a href="{{ .Name }}.ics">Calendar</a>
+</li>
+{{- end }}
+</ul>
+<p><a href="{{ .OPMLFile }}">OPML</a> of every feed, to import into your RSS app</p>
+</body>
+</html>
+`))
+
+// writeIndex writes the index page of the site, linking to every feed
+func writeIndex(siteFeeds []siteFeed, options Options) error {
+	data := struct {
+		Title    string
+		OPMLFile string
+		Feeds    []siteFeed
+	}{
+		Title:    siteTitle,
+		OPMLFile: opmlFile,
+		Feeds:    siteFeeds,
+	}
+
+	var index bytes.Buffer
+	err := indexTemplate.Execute(&index, data)
+	if err != nil {
+		return fmt.Errorf("failed to render index: %w", err)
+	}
+
+	return utils.WriteFile(filepath.Join(options.OutputDir, indexFile), index.Bytes(), 0o644)
+}
+
+type opml struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Feeds   []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+}
+
+// writeOPML writes an OPML file of the RSS feeds of the site, so every feed can be imported into an RSS app at once
+func writeOPML(siteFeeds []siteFeed, options Options) error {
+	outlines := make([]opmlOutline, 0, len(siteFeeds))
+	for _, siteFeed := range siteFeeds {
+		feedUrl, err := siteURL(options.BaseURL, siteFeed.Name+".rss")
+		if err != nil {
+			return err
+		}
+
+		outlines = append(outlines, opmlOutline{
+			Type:    "rss",
+			Text:    siteFeed.Title,
+			Title:   siteFeed.Title,
+			XMLURL:  feedUrl,
+			HTMLURL: siteFeed.Link,
+		})
+	}
+
+	opmlBytes, err := xml.MarshalIndent(opml{Version: "2.0", Title: siteTitle, Feeds: outlines}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode opml: %w", err)
+	}
+	opmlBytes = append([]byte(xml.Header), opmlBytes...)
+
+	return utils.WriteFile(filepath.Join(options.OutputDir, opmlFile), opmlBytes, 0o644)
+}
+
+// siteURL returns the url of a file of the site. If there is no base url, the file name is returned as a relative url.
+func siteURL(baseUrl, fileName string) (string, error) {
+	if baseUrl == "" {
+		return fileName, nil
+	}
+
+	fileUrl, err := url.JoinPath(baseUrl, fileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url: %w", err)
+	}
+
+	return fileUrl, nil
+}
@@ -0,0 +1,107 @@
+package site_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/site"
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestGenerate(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	outputDir := t.TempDir()
+	store := t4g.NewFileFeedStore(filepath.Join(t.TempDir(), "state.json"))
+	options := site.Options{OutputDir: outputDir, BaseURL: "https://example.com/feeds/", EventPages: 1}
+	inputs := []t4g.FeedInput{{Location: lo.ToPtr("London")}, {Location: lo.ToPtr("Milton Keynes")}}
+
+	firstRun := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := upstream.Client(t, t4g.WithClock(func() time.Time { return firstRun }))
+	err := site.Generate(context.Background(), client, store, inputs, options)
+	require.NoError(t, err)
+
+	for _, file := range []string{"london.rss", "london.atom", "london.json", "london.ics", "milton-keynes.rss"} {
+		require.FileExists(t, filepath.Join(outputDir, file))
+	}
+	require.Contains(t, readFile(t, filepath.Join(outputDir, "london.atom")), "<title>T4G Feed: London</title>")
+
+	// Only the pages and feeds of the site should be published
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		isFeed := lo.Contains([]string{".rss", ".atom", ".json", ".ics"}, filepath.Ext(entry.Name()))
+		isPage := lo.Contains([]string{"index.html", "feeds.opml"}, entry.Name())
+		require.True(t, isFeed || isPage, "unexpected file %s in output dir", entry.Name())
+	}
+
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	require.Contains(t, index, `<a href="milton-keynes.rss">RSS</a>`)
+	require.Contains(t, index, "T4G Feed: Milton Keynes")
+
+	opml := readFile(t, filepath.Join(outputDir, "feeds.opml"))
+	require.Contains(t, opml, `xmlUrl="https://example.com/feeds/london.rss"`)
+	require.Contains(t, opml, `text="T4G Feed: Milton Keynes"`)
+
+	// Generating again should keep the history of the feeds, so items keep the time they were created
+	londonRss := readFile(t, filepath.Join(outputDir, "london.rss"))
+	client = upstream.Client(t, t4g.WithClock(func() time.Time { return firstRun.Add(time.Hour) }))
+	err = site.Generate(context.Background(), client, store, inputs, options)
+	require.NoError(t, err)
+	require.Equal(t, londonRss, readFile(t, filepath.Join(outputDir, "london.rss")))
+
+	snapshot, err := store.LoadFeed(client.FeedKey(inputs[0]))
+	require.NoError(t, err)
+	require.Equal(t, firstRun, snapshot.Items[0].Created.UTC())
+	require.Equal(t, firstRun.Add(time.Hour), snapshot.Refreshed.UTC())
+}
+
+func TestGenerateUnavailable(t *testing.T) {
+	upstream := t4gtest.NewServer(t)
+	client := upstream.Client(t)
+	outputDir := t.TempDir()
+	store := t4g.NewFileFeedStore(filepath.Join(t.TempDir(), "state.json"))
+	options := site.Options{OutputDir: outputDir, EventPages: 1}
+
+	err := site.Generate(context.Background(), client, store, []t4g.FeedInput{{Location: lo.ToPtr("london")}}, options)
+	require.NoError(t, err)
+	londonRss := readFile(t, filepath.Join(outputDir, "london.rss"))
+
+	// Feeds generated before are still written, while new feeds are left out of the site
+	upstream.SetStatus(http.StatusServiceUnavailable)
+	inputs := []t4g.FeedInput{{Location: lo.ToPtr("london")}, {Location: lo.ToPtr("leeds")}}
+	err = site.Generate(context.Background(), client, store, inputs, options)
+	require.ErrorContains(t, err, "failed to update feed leeds")
+
+	require.Equal(t, londonRss, readFile(t, filepath.Join(outputDir, "london.rss")))
+	require.NoFileExists(t, filepath.Join(outputDir, "leeds.rss"))
+	require.Contains(t, readFile(t, filepath.Join(outputDir, "feeds.opml")), `xmlUrl="london.rss"`)
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	client := t4gtest.NewServer(t).Client(t)
+	store := t4g.NewFileFeedStore(filepath.Join(t.TempDir(), "state.json"))
+	options := site.Options{OutputDir: t.TempDir(), EventPages: 1}
+
+	inputs := []t4g.FeedInput{{Location: lo.ToPtr("London")}, {Location: lo.ToPtr("london")}}
+	err := site.Generate(context.Background(), client, store, inputs, options)
+	require.ErrorContains(t, err, `more than one feed has the name "london"`)
+
+	err = site.Generate(context.Background(), client, store, []t4g.FeedInput{{Location: lo.ToPtr("!")}}, options)
+	require.ErrorContains(t, err, "must contain letters or numbers")
+}
@@ -0,0 +1,232 @@
+package t4g
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	DefaultEventPages     = 5  // Default number of event pages to get when updating a feed
+	DefaultMaxFeedItems   = 75 // Default maximum number of items in a feed
+	DefaultMaxCachedFeeds = 10 // Default maximum number of feeds to keep in the cache
+)
+
+// Client fetches events and feeds from Tickets For Good.
+// Clients are independent of each other, each having its own configuration,
+// feed cache, event detail cache, background refresh and listeners.
+type Client struct {
+	upstream       *UpstreamClient
+	portals        map[string]Portal // Portal name -> portal
+	defaultPortal  string
+	eventPages     int
+	maxFeedItems   int
+	maxCachedFeeds int
+	now            func() time.Time
+	logger         *slog.Logger
+	store          FeedStore
+	metrics        *metrics
+
+	cachedFeeds      map[string]*Feed // Feed input key -> feed
+	cachedFeedsMutex sync.Mutex       // Guards cachedFeeds only. Feeds are refreshed without holding it.
+
+	feedRefreshes      map[string]*feedRefresh // Feed input key -> in progress refresh
+	feedRefreshesMutex sync.Mutex
+
+	cachedEventDetails      map[int]cachedEventDetail // Event id -> event detail
+	cachedEventDetailsMutex sync.Mutex
+
+	// eventDetailSemaphore bounds the number of event pages fetched at once,
+	// across all feeds, so updating feeds does not hammer the site.
+	eventDetailSemaphore chan struct{}
+
+	backgroundRefresh      *backgroundRefresher
+	backgroundRefreshMutex sync.RWMutex
+
+	scrapeStatus      ScrapeStatus
+	scrapeStatusMutex sync.RWMutex
+
+	newEventsListeners      []NewEventsListener
+	newEventsListenersMutex sync.RWMutex
+}
+
+// Option configures a client
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient     *http.Client
+	upstream       *UpstreamClient
+	baseUrl        string
+	portalUrls     map[string]string
+	defaultPortal  string
+	eventPages     int
+	maxFeedItems   int
+	maxCachedFeeds int
+	now            func() time.Time
+	logger         *slog.Logger
+	store          FeedStore
+	registerer     prometheus.Registerer
+}
+
+// WithHTTPClient sets the http client used to make requests to Tickets For Good.
+// This takes precedence over the http client of WithUpstreamClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) { o.httpClient = httpClient }
+}
+
+// WithUpstreamClient sets how requests are made to Tickets For Good e.g. timeouts and retries.
+// By default DefaultUpstreamClient is used.
+func WithUpstreamClient(upstream *UpstreamClient) Option {
+	return func(o *clientOptions) { o.upstream = upstream }
+}
+
+// WithBaseURL sets the base url of the default portal e.g. to use a local mirror of Tickets For Good
+func WithBaseURL(baseUrl string) Option {
+	return func(o *clientOptions) { o.baseUrl = baseUrl }
+}
+
+// WithPortals sets the portals feeds can be fetched from, as a map of portal name to base url.
+// The default portal is used when a portal is not chosen, so must be one of the portals.
+// By default only the NHS portal is allowed.
+func WithPortals(portalUrls map[string]string, defaultPortal string) Option {
+	return func(o *clientOptions) {
+		o.portalUrls = portalUrls
+		o.defaultPortal = defaultPortal
+	}
+}
+
+// WithEventPages sets the number of event pages to get when updating a feed
+func WithEventPages(pages int) Option {
+	return func(o *clientOptions) { o.eventPages = pages }
+}
+
+// WithMaxFeedItems sets the maximum number of items in a feed
+func WithMaxFeedItems(items int) Option {
+	return func(o *clientOptions) { o.maxFeedItems = items }
+}
+
+// WithMaxCachedFeeds sets the maximum number of feeds to keep in the cache
+func WithMaxCachedFeeds(feeds int) Option {
+	return func(o *clientOptions) { o.maxCachedFeeds = feeds }
+}
+
+// WithClock sets the function used to get the current time e.g. when recording when feeds were refreshed
+func WithClock(now func() time.Time) Option {
+	return func(o *clientOptions) { o.now = now }
+}
+
+// WithLogger sets the logger of the client. By default slog.Default is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) { o.logger = logger }
+}
+
+// WithFeedStore sets the store that feeds are loaded from and saved to.
+// By default there is no store, so feeds are only kept in memory.
+func WithFeedStore(store FeedStore) Option {
+	return func(o *clientOptions) { o.store = store }
+}
+
+// WithRegisterer sets the registerer the prometheus metrics of the client are registered with.
+// Clients registered with the same registerer share their metrics.
+// By default metrics are not registered, so are not exported.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *clientOptions) { o.registerer = registerer }
+}
+
+// NewClient creates a client configured with options
+func NewClient(opts ...Option) (*Client, error) {
+	options := clientOptions{
+		portalUrls:     DefaultPortals(),
+		defaultPortal:  DefaultPortalName,
+		eventPages:     DefaultEventPages,
+		maxFeedItems:   DefaultMaxFeedItems,
+		maxCachedFeeds: DefaultMaxCachedFeeds,
+		now:            time.Now,
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var errs []error
+	if options.eventPages < 1 {
+		errs = append(errs, errors.New("event pages must be at least 1"))
+	}
+	if options.maxFeedItems < 1 {
+		errs = append(errs, errors.New("max feed items must be at least 1"))
+	}
+	if options.maxCachedFeeds < 1 {
+		errs = append(errs, errors.New("max cached feeds must be at least 1"))
+	}
+	if options.now == nil || options.logger == nil {
+		errs = append(errs, errors.New("clock and logger must not be nil"))
+	}
+
+	// Copy the portals so the base url does not modify the map passed in
+	portalUrls := make(map[string]string, len(options.portalUrls))
+	for name, portalUrl := range options.portalUrls {
+		portalUrls[name] = portalUrl
+	}
+	if options.baseUrl != "" {
+		portalUrls[options.defaultPortal] = options.baseUrl
+	}
+
+	portals, err := newPortals(portalUrls)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if _, ok := portalUrls[options.defaultPortal]; !ok {
+		errs = append(errs, fmt.Errorf("default portal %q is not one of the portals", options.defaultPortal))
+	}
+
+	metrics, err := newMetrics(options.registerer)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to register metrics: %w", err))
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid client options: %w", errors.Join(errs...))
+	}
+
+	// Copy the upstream client so it cannot be changed after the client is created
+	upstream := DefaultUpstreamClient()
+	if options.upstream != nil {
+		*upstream = *options.upstream
+	}
+	if options.httpClient != nil {
+		upstream.HTTPClient = options.httpClient
+	}
+	upstream.metrics = metrics
+
+	return &Client{
+		upstream:             upstream,
+		portals:              portals,
+		defaultPortal:        options.defaultPortal,
+		eventPages:           options.eventPages,
+		maxFeedItems:         options.maxFeedItems,
+		maxCachedFeeds:       options.maxCachedFeeds,
+		now:                  options.now,
+		logger:               options.logger,
+		store:                options.store,
+		metrics:              metrics,
+		cachedFeeds:          map[string]*Feed{},
+		feedRefreshes:        map[string]*feedRefresh{},
+		cachedEventDetails:   map[int]cachedEventDetail{},
+		eventDetailSemaphore: make(chan struct{}, maxConcurrentEventDetailFetches),
+	}, nil
+}
+
+// Now returns the current time, according to the clock of the client
+func (c *Client) Now() time.Time {
+	return c.now()
+}
+
+// since returns the time elapsed since a time, according to the clock of the client
+func (c *Client) since(t time.Time) time.Duration {
+	return c.now().Sub(t)
+}
@@ -0,0 +1,90 @@
+package t4g_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientInvalidOptions(t *testing.T) {
+	_, err := t4g.NewClient(t4g.WithEventPages(0), t4g.WithMaxFeedItems(0))
+	require.ErrorContains(t, err, "event pages must be at least 1")
+	require.ErrorContains(t, err, "max feed items must be at least 1")
+
+	_, err = t4g.NewClient(t4g.WithBaseURL("localhost:8080"))
+	require.ErrorContains(t, err, "must be an absolute http or https url")
+}
+
+func TestClientMetrics(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	registry := prometheus.NewRegistry()
+
+	// Clients registered with the same registerer should share their metrics rather than fail to register
+	client1 := server.Client(t, t4g.WithRegisterer(registry))
+	client2 := server.Client(t, t4g.WithRegisterer(registry))
+
+	for _, client := range []*t4g.Client{client1, client2} {
+		_, err := client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr("metrics")}, nil)
+		require.NoError(t, err)
+	}
+
+	metricFamilies, err := registry.Gather()
+	require.NoError(t, err)
+	requests := lo.Filter(metricFamilies, func(family *dto.MetricFamily, _ int) bool {
+		return family.GetName() == "t4g_upstream_requests_total"
+	})
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0].GetMetric(), 1)
+	require.Equal(t, float64(2+2*t4gtest.Page1Events), requests[0].GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestClientsAreIndependent(t *testing.T) {
+	server1 := t4gtest.NewServer(t)
+	server2 := t4gtest.NewServer(t)
+	client1 := server1.Client(t)
+	client2 := server2.Client(t)
+
+	debounceTime := time.Hour
+	input := t4g.FeedInput{Location: lo.ToPtr("independent")}
+
+	feed1, err := client1.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+	feed2, err := client2.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+
+	// Each client has its own feed cache, and fetches from its own site
+	require.NotSame(t, feed1, feed2)
+	require.Equal(t, 1, server1.PageRequests("independent", 1))
+	require.Equal(t, 1, server2.PageRequests("independent", 1))
+	require.Equal(t, server1.URL+"/events/"+strconv.Itoa(t4gtest.FirstEventId), feed1.Events()[0].Link)
+	require.Equal(t, server2.URL+"/events/"+strconv.Itoa(t4gtest.FirstEventId), feed2.Events()[0].Link)
+}
+
+func TestClientClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := t4gtest.NewServer(t).Client(t, t4g.WithClock(func() time.Time { return now }))
+
+	feed, err := client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr("clock")}, nil)
+	require.NoError(t, err)
+	require.Equal(t, now, feed.RefreshedAt())
+	require.Equal(t, now, feed.UpdatedAt())
+	require.Equal(t, now, feed.Snapshot().Items[0].Created)
+}
+
+func TestClientBaseURL(t *testing.T) {
+	client, err := t4g.NewClient(t4g.WithBaseURL("http://localhost:8080/t4g/"))
+	require.NoError(t, err)
+
+	require.Equal(t, "http://localhost:8080/t4g", client.DefaultPortal().URL.String())
+	require.Contains(
+		t, client.EventsUrl(t4g.EventsInput{Location: lo.ToPtr("london")}), "http://localhost:8080/t4g/events?",
+	)
+}
@@ -0,0 +1,260 @@
+package t4g
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	_ "time/tzdata" // Embed timezone data so Europe/London can always be loaded
+)
+
+var (
+	// londonLocation is the timezone of event dates on Tickets For Good
+	londonLocation *time.Location
+
+	// ordinalRegex matches ordinal suffixes of day numbers e.g. 1st, 2nd
+	ordinalRegex = regexp.MustCompile(`(\d)(?:ST|ND|RD|TH)\b`)
+
+	// dateRangeRegex matches the separator between the start and end of a date range
+	dateRangeRegex = regexp.MustCompile(`\s*[-–]\s*|\s+(?:TO|UNTIL)\s+`)
+
+	// multipleDatesRegex matches text stating an event has multiple dates
+	multipleDatesRegex = regexp.MustCompile(`MULTIPLE\s+DATES?:?`)
+)
+
+// Layouts of dates and times. Dates are upper cased before parsing, so PM is used rather than pm.
+var (
+	dateLayouts = []string{
+		"Mon 2 Jan 2006",
+		"Mon 2 January 2006",
+		"Monday 2 Jan 2006",
+		"Monday 2 January 2006",
+		"2 Jan 2006",
+		"2 January 2006",
+		"02/01/2006",
+	}
+	yearlessDateLayouts = []string{
+		"Mon 2 Jan",
+		"Mon 2 January",
+		"Monday 2 Jan",
+		"Monday 2 January",
+		"2 Jan",
+		"2 January",
+	}
+	timeLayouts = []string{
+		"15:04",
+		"3:04PM",
+		"3:04 PM",
+		"3PM",
+		"3 PM",
+	}
+)
+
+func init() {
+	var err error
+	londonLocation, err = time.LoadLocation("Europe/London")
+	if err != nil {
+		log.Fatal("failed to load europe/london timezone")
+	}
+}
+
+// DateParseError is returned when an event date cannot be parsed
+type DateParseError struct {
+	Date string
+	Err  error
+}
+
+func (e *DateParseError) Error() string {
+	return fmt.Sprintf("failed to parse date %q: %s", e.Date, e.Err)
+}
+
+func (e *DateParseError) Unwrap() error { return e.Err }
+
+// EventDates are the dates of an event parsed from its date string
+type EventDates struct {
+	StartsAt      time.Time
+	EndsAt        time.Time // Zero if the event has no known end
+	AllDay        bool      // Whether the dates have no times
+	MultipleDates bool      // Whether the event runs on multiple dates
+}
+
+// ParseEventDates parses an event date string as shown on Tickets For Good.
+// It handles single dates (with or without a time), date ranges and events with multiple dates.
+// Dates without a year are assumed to be in the year closest to now.
+func ParseEventDates(date string, now time.Time) (EventDates, error) {
+	normalisedDate := normaliseDate(date)
+	if normalisedDate == "" {
+		return EventDates{}, &DateParseError{Date: date, Err: errors.New("date is empty")}
+	}
+
+	var dates EventDates
+
+	// Events with multiple dates may not list any dates at all
+	if multipleDatesRegex.MatchString(normalisedDate) {
+		dates.MultipleDates = true
+
+		normalisedDate = strings.TrimSpace(multipleDatesRegex.ReplaceAllString(normalisedDate, ""))
+		normalisedDate = strings.Trim(normalisedDate, "()- ")
+		if normalisedDate == "" {
+			return dates, nil
+		}
+	}
+
+	rangeParts := dateRangeRegex.Split(normalisedDate, 2)
+
+	// The start of a range might not have a year e.g. 30 Dec - 2 Jan 2024.
+	// If the end of the range has a date, use it as the reference for the start
+	var startReference *time.Time
+	if len(rangeParts) == 2 {
+		end, err := parseDateTime(rangeParts[1], nil, now)
+		if err == nil && end.hasDate && !end.inferredYear {
+			startReference = &end.time
+		}
+	}
+
+	start, err := parseDateTime(rangeParts[0], startReference, now)
+	if err == nil && start.inferredYear && startReference != nil && start.time.After(*startReference) {
+		// Range spans the new year
+		start.time = start.time.AddDate(-1, 0, 0)
+	}
+	if err != nil {
+		return EventDates{}, &DateParseError{Date: date, Err: err}
+	}
+	if !start.hasDate {
+		return EventDates{}, &DateParseError{Date: date, Err: errors.New("date has no day")}
+	}
+
+	dates.StartsAt = start.time
+	dates.AllDay = !start.hasTime
+
+	if len(rangeParts) == 2 {
+		end, err := parseDateTime(rangeParts[1], &start.time, now)
+		if err != nil {
+			return EventDates{}, &DateParseError{Date: date, Err: err}
+		}
+
+		if end.time.Before(start.time) {
+			switch {
+			case !end.hasDate:
+				// End time is past midnight e.g. 10pm - 2am
+				end.time = end.time.AddDate(0, 0, 1)
+			case end.inferredYear:
+				// Range spans the new year e.g. 30 Dec 2023 - 2 Jan
+				end.time = end.time.AddDate(1, 0, 0)
+			default:
+				return EventDates{}, &DateParseError{Date: date, Err: errors.New("end is before start")}
+			}
+		}
+
+		dates.EndsAt = end.time
+	}
+
+	return dates, nil
+}
+
+type parsedDateTime struct {
+	time         time.Time
+	hasDate      bool
+	hasTime      bool
+	inferredYear bool
+}
+
+// parseDateTime parses a date, time or date and time. If a reference time is passed,
+// it is used to fill in any missing date or year, otherwise missing years are inferred from now.
+func parseDateTime(value string, reference *time.Time, now time.Time) (parsedDateTime, error) {
+	// Try dates with years and optional times
+	for _, dateLayout := range dateLayouts {
+		if parsed, ok := parseWithTimeLayouts(value, dateLayout); ok {
+			parsed.hasDate = true
+			return parsed, nil
+		}
+	}
+
+	// Try dates without years and optional times
+	for _, dateLayout := range yearlessDateLayouts {
+		if parsed, ok := parseWithTimeLayouts(value, dateLayout); ok {
+			parsed.hasDate = true
+			parsed.inferredYear = true
+			parsed.time = withYear(parsed.time, reference, now)
+			return parsed, nil
+		}
+	}
+
+	// Try times only. These need a reference date
+	if reference != nil {
+		for _, timeLayout := range timeLayouts {
+			parsedTime, err := time.ParseInLocation(timeLayout, value, londonLocation)
+			if err != nil {
+				continue
+			}
+
+			return parsedDateTime{
+				time: time.Date(
+					reference.Year(), reference.Month(), reference.Day(),
+					parsedTime.Hour(), parsedTime.Minute(), 0, 0, londonLocation,
+				),
+				hasTime: true,
+			}, nil
+		}
+	}
+
+	return parsedDateTime{}, fmt.Errorf("unknown date format %q", value)
+}
+
+// parseWithTimeLayouts parses a value with a date layout on its own, or followed by any of the time layouts
+func parseWithTimeLayouts(value, dateLayout string) (parsedDateTime, bool) {
+	parsedTime, err := time.ParseInLocation(dateLayout, value, londonLocation)
+	if err == nil {
+		return parsedDateTime{time: parsedTime}, true
+	}
+
+	for _, timeLayout := range timeLayouts {
+		parsedTime, err := time.ParseInLocation(dateLayout+" "+timeLayout, value, londonLocation)
+		if err == nil {
+			return parsedDateTime{time: parsedTime, hasTime: true}, true
+		}
+	}
+
+	return parsedDateTime{}, false
+}
+
+// withYear sets the year of a time parsed without one. If a reference time is passed its year is used,
+// otherwise the year that puts the time closest to now is used.
+func withYear(t time.Time, reference *time.Time, now time.Time) time.Time {
+	setYear := func(year int) time.Time {
+		return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, londonLocation)
+	}
+
+	if reference != nil {
+		return setYear(reference.Year())
+	}
+
+	now = now.In(londonLocation)
+	closest := setYear(now.Year())
+	for _, year := range []int{now.Year() - 1, now.Year() + 1} {
+		candidate := setYear(year)
+		if absDuration(candidate.Sub(now)) < absDuration(closest.Sub(now)) {
+			closest = candidate
+		}
+	}
+
+	return closest
+}
+
+// normaliseDate normalises a date string so it can be parsed using the known layouts
+func normaliseDate(date string) string {
+	normalisedDate := strings.ToUpper(date)
+	normalisedDate = strings.NewReplacer(",", " ", " AT ", " ", ".", ":").Replace(normalisedDate)
+	normalisedDate = ordinalRegex.ReplaceAllString(normalisedDate, "$1")
+	return strings.Join(strings.Fields(normalisedDate), " ")
+}
+
+func absDuration(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+	return duration
+}
@@ -0,0 +1,104 @@
+package t4g_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventDates(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+	now := time.Date(2024, 12, 20, 12, 0, 0, 0, london)
+
+	tests := map[string]struct {
+		date     string
+		expected t4g.EventDates
+	}{
+		"date with time": {
+			date: "Sat 20 Jan 2024\n7:30pm",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2024, 1, 20, 19, 30, 0, 0, london),
+			},
+		},
+		"date with 24 hour time in summer time": {
+			date: "Saturday 20th July 2024 at 19:30",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2024, 7, 20, 19, 30, 0, 0, london),
+			},
+		},
+		"date without time": {
+			date: "20 Jan 2024",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2024, 1, 20, 0, 0, 0, 0, london),
+				AllDay:   true,
+			},
+		},
+		"date range": {
+			date: "Fri 19 Jan 2024 - Sun 21 Jan 2024",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2024, 1, 19, 0, 0, 0, 0, london),
+				EndsAt:   time.Date(2024, 1, 21, 0, 0, 0, 0, london),
+				AllDay:   true,
+			},
+		},
+		"date range with start missing year": {
+			date: "30 Dec - 2 Jan 2024",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2023, 12, 30, 0, 0, 0, 0, london),
+				EndsAt:   time.Date(2024, 1, 2, 0, 0, 0, 0, london),
+				AllDay:   true,
+			},
+		},
+		"date missing year": {
+			date: "Sat 4 Jan 7:30pm",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2025, 1, 4, 19, 30, 0, 0, london),
+			},
+		},
+		"time range": {
+			date: "Sat 20 Jan 2024 10pm - 2am",
+			expected: t4g.EventDates{
+				StartsAt: time.Date(2024, 1, 20, 22, 0, 0, 0, london),
+				EndsAt:   time.Date(2024, 1, 21, 2, 0, 0, 0, london),
+			},
+		},
+		"multiple dates": {
+			date: "Multiple Dates",
+			expected: t4g.EventDates{
+				MultipleDates: true,
+			},
+		},
+		"multiple dates with range": {
+			date: "Multiple dates: 1 Feb 2024 to 3 Mar 2024",
+			expected: t4g.EventDates{
+				StartsAt:      time.Date(2024, 2, 1, 0, 0, 0, 0, london),
+				EndsAt:        time.Date(2024, 3, 3, 0, 0, 0, 0, london),
+				AllDay:        true,
+				MultipleDates: true,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dates, err := t4g.ParseEventDates(test.date, now)
+			require.NoError(t, err)
+			require.True(t, test.expected.StartsAt.Equal(dates.StartsAt), "got start %s", dates.StartsAt)
+			require.True(t, test.expected.EndsAt.Equal(dates.EndsAt), "got end %s", dates.EndsAt)
+			require.Equal(t, test.expected.AllDay, dates.AllDay)
+			require.Equal(t, test.expected.MultipleDates, dates.MultipleDates)
+		})
+	}
+}
+
+func TestParseEventDatesError(t *testing.T) {
+	for _, date := range []string{"", "Coming soon", "5 Jan 2024 - 1 Jan 2024"} {
+		_, err := t4g.ParseEventDates(date, time.Now())
+
+		var dateParseError *t4g.DateParseError
+		require.ErrorAs(t, err, &dateParseError, date)
+	}
+}
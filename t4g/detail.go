@@ -0,0 +1,193 @@
+package t4g
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	maxCachedEventDetails           = 1000
+	maxConcurrentEventDetailFetches = 4
+)
+
+// EventDetail is the detail of an event found on its event page.
+// The selectors have not yet been checked against a recorded event page, so fields may be empty.
+type EventDetail struct {
+	Description    string   `pagser:".event-description->html()"`
+	VenueAddress   string   `pagser:".venue-address"`
+	Performances   []string `pagser:".performance-time"`
+	TicketLimit    string   `pagser:".ticket-limit"`
+	AgeRestriction string   `pagser:".age-restriction"`
+}
+
+type cachedEventDetail struct {
+	detail   *EventDetail
+	cachedAt time.Time
+}
+
+// sanitise will sanitise an event detail after being parsed from html
+func (d EventDetail) sanitise() EventDetail {
+	detail := d
+
+	detail.Description = strings.TrimSpace(d.Description)
+	detail.VenueAddress = strings.Join(strings.Fields(d.VenueAddress), " ")
+	detail.TicketLimit = strings.TrimSpace(d.TicketLimit)
+	detail.AgeRestriction = strings.TrimSpace(d.AgeRestriction)
+
+	detail.Performances = make([]string, 0, len(d.Performances))
+	for _, performance := range d.Performances {
+		performance = strings.Join(strings.Fields(performance), " ")
+		if performance != "" {
+			detail.Performances = append(detail.Performances, performance)
+		}
+	}
+
+	return detail
+}
+
+// descriptionPolicy is the policy used to sanitise event descriptions, which are html scraped from event pages.
+// Formatting and links are kept, while scripts, styles and event handlers are removed.
+var descriptionPolicy = bluemonday.UGCPolicy()
+
+// eventDetailContentTemplate is the template used to render an event detail as html
+var eventDetailContentTemplate = template.Must(template.New("content").Parse(
+	`{{- with .Description }}<div>{{ . }}</div>{{ end -}}
+{{- with .VenueAddress }}<h3>Venue</h3><p>{{ . }}</p>{{ end -}}
+{{- with .Performances }}<h3>Performances</h3><ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>{{ end -}}
+{{- with .TicketLimit }}<p><strong>Ticket limit:</strong> {{ . }}</p>{{ end -}}
+{{- with .AgeRestriction }}<p><strong>Age restriction:</strong> {{ . }}</p>{{ end -}}`,
+))
+
+// HTML renders the event detail as html, suitable for use as feed item content.
+// The description is html from the event page, so is sanitised rather than escaped.
+func (d *EventDetail) HTML() (string, error) {
+	data := struct {
+		EventDetail
+		Description template.HTML
+	}{
+		EventDetail: *d,
+		Description: template.HTML(descriptionPolicy.Sanitize(d.Description)),
+	}
+
+	var content bytes.Buffer
+	err := eventDetailContentTemplate.Execute(&content, data)
+	if err != nil {
+		return "", err
+	}
+
+	return content.String(), nil
+}
+
+// FetchEventDetail fetches the detail of an event from its event page.
+// Event details are cached by event id.
+func (c *Client) FetchEventDetail(ctx context.Context, event Event) (*EventDetail, error) {
+	if detail, ok := c.cachedEventDetailFor(event.Id); ok {
+		return detail, nil
+	}
+
+	// Wait for a free slot to fetch the event page
+	select {
+	case c.eventDetailSemaphore <- struct{}{}:
+		defer func() { <-c.eventDetailSemaphore }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	eventPage, err := c.getPage(ctx, event.Link)
+	if err != nil {
+		return nil, err
+	}
+
+	var detail EventDetail
+	err = NewHTMLParser().Parse(&detail, eventPage)
+	if err != nil {
+		c.metrics.parseFailures.WithLabelValues(parseFailureEventPage).Inc()
+		return nil, err
+	}
+	detail = detail.sanitise()
+
+	c.cacheEventDetail(event.Id, &detail)
+
+	return &detail, nil
+}
+
+// FetchEventDetails fetches the details of many events concurrently, returning a map of event id to event detail.
+// Events whose detail cannot be fetched are logged and left out of the map.
+func (c *Client) FetchEventDetails(ctx context.Context, events []Event) map[int]*EventDetail {
+	details := make(map[int]*EventDetail, len(events))
+	var detailsMutex sync.Mutex
+
+	var wg sync.WaitGroup
+	for _, event := range events {
+		if event.Link == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(event Event) {
+			defer wg.Done()
+
+			detail, err := c.FetchEventDetail(ctx, event)
+			if err != nil {
+				c.logger.WarnContext(ctx, "Failed to fetch event detail", "eventId", event.Id, "error", err)
+				return
+			}
+
+			detailsMutex.Lock()
+			details[event.Id] = detail
+			detailsMutex.Unlock()
+		}(event)
+	}
+	wg.Wait()
+
+	return details
+}
+
+func (c *Client) cachedEventDetailFor(eventId int) (*EventDetail, bool) {
+	if eventId == 0 {
+		return nil, false
+	}
+
+	c.cachedEventDetailsMutex.Lock()
+	defer c.cachedEventDetailsMutex.Unlock()
+
+	cached, ok := c.cachedEventDetails[eventId]
+	return cached.detail, ok
+}
+
+func (c *Client) cacheEventDetail(eventId int, detail *EventDetail) {
+	if eventId == 0 {
+		return
+	}
+
+	c.cachedEventDetailsMutex.Lock()
+	defer c.cachedEventDetailsMutex.Unlock()
+
+	c.cachedEventDetails[eventId] = cachedEventDetail{detail: detail, cachedAt: c.now()}
+
+	// Keep the number of cached event details bounded
+	if len(c.cachedEventDetails) > maxCachedEventDetails {
+		deleteOldestCachedEventDetail(c.cachedEventDetails)
+	}
+}
+
+func deleteOldestCachedEventDetail(cachedEventDetails map[int]cachedEventDetail) {
+	var oldestKey int
+	var oldestTime time.Time
+
+	// Find the oldest cached event detail
+	for key, cached := range cachedEventDetails {
+		if oldestKey == 0 || cached.cachedAt.Before(oldestTime) {
+			oldestKey = key
+			oldestTime = cached.cachedAt
+		}
+	}
+
+	delete(cachedEventDetails, oldestKey)
+}
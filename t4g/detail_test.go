@@ -0,0 +1,64 @@
+package t4g_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/stretchr/testify/require"
+)
+
+const eventPage = `
+<html>
+  <body>
+    <div class="event-description"><p>A <em>great</em> show</p></div>
+    <div class="venue-address">The Globe, 21 New Globe Walk, London</div>
+    <ul>
+      <li class="performance-time">Sat 20 Jan 2024 2:30pm</li>
+      <li class="performance-time">Sat 20 Jan 2024 7:30pm</li>
+    </ul>
+    <div class="ticket-limit">2 tickets per member</div>
+    <div class="age-restriction">16+ & over</div>
+  </body>
+</html>
+`
+
+func TestEventDetailHTML(t *testing.T) {
+	var detail t4g.EventDetail
+	err := t4g.NewHTMLParser().Parse(&detail, eventPage)
+	require.NoError(t, err)
+
+	require.Equal(t, "The Globe, 21 New Globe Walk, London", detail.VenueAddress)
+	require.Equal(t, []string{"Sat 20 Jan 2024 2:30pm", "Sat 20 Jan 2024 7:30pm"}, detail.Performances)
+
+	content, err := detail.HTML()
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`<div><p>A <em>great</em> show</p></div>`+
+			`<h3>Venue</h3><p>The Globe, 21 New Globe Walk, London</p>`+
+			`<h3>Performances</h3><ul><li>Sat 20 Jan 2024 2:30pm</li><li>Sat 20 Jan 2024 7:30pm</li></ul>`+
+			`<p><strong>Ticket limit:</strong> 2 tickets per member</p>`+
+			`<p><strong>Age restriction:</strong> 16&#43; &amp; over</p>`,
+		content,
+	)
+}
+
+func TestEventDetailHTMLSanitisesDescription(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	event := t4g.Event{Id: t4gtest.FirstEventId, Link: server.URL + "/events/" + strconv.Itoa(t4gtest.FirstEventId)}
+
+	detail, err := server.Client(t).FetchEventDetail(context.Background(), event)
+	require.NoError(t, err)
+	require.Contains(t, detail.Description, "<script>")
+
+	// Scripts and event handlers in the description of the event page should be removed, keeping formatting
+	content, err := detail.HTML()
+	require.NoError(t, err)
+	require.Contains(t, content, "<p>Tickets are available for this <strong>fantastic</strong> event.</p>")
+	require.Contains(t, content, "Find out more")
+	require.NotContains(t, content, "script")
+	require.NotContains(t, content, "alert")
+}
@@ -3,10 +3,11 @@ package t4g
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
-	"github.com/ahobsonsayers/t4g-feed/utils"
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/samber/lo"
 )
@@ -25,26 +26,56 @@ type Event struct {
 	Location string `pagser:".card-body .col->eq(0)"`
 	Date     string `pagser:".card-body .col->eq(1)"`
 	Category string `pagser:".card-body .col->eq(2)"`
+
+	// Dates parsed from the raw date string. Parsing is best effort, so
+	// these may be zero if the date string could not be parsed.
+	EventDates
 }
 
-// sanitise will sanitise an event after being parsed from html
-func (e Event) sanitise() Event {
+// sanitise will sanitise an event after being parsed from html of a portal
+func (e Event) sanitise(portal Portal) Event {
 	event := e
 
 	event.Image = strings.ReplaceAll(e.Image, "thumb_", "")
-
-	link := utils.CloneURL(ticketsForGoodUrl)
-	link.Path = e.Link
-	event.Link = link.String()
+	event.Link = portal.URL.JoinPath(e.Link).String()
 
 	event.Date = strings.ReplaceAll(e.Date, "\n", " ")
 
 	return event
 }
 
-func Events(ctx context.Context, location *string, pages *int) ([]Event, error) {
-	eventPages, err := manyPageEvents(ctx, location, pages)
-	if err != nil {
+// PartialError is returned when some, but not all, event pages could not be fetched.
+// The events of the pages that were fetched are still returned alongside it.
+type PartialError struct {
+	PageErrors map[int]error // Page number -> error getting the page
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("failed to get %d event pages: %s", len(e.PageErrors), joinPageErrors(e.PageErrors))
+}
+
+func (e *PartialError) Unwrap() []error {
+	return lo.Values(e.PageErrors)
+}
+
+// FailedPages returns the page numbers of the pages that could not be fetched, in order
+func (e *PartialError) FailedPages() []int {
+	pages := lo.Keys(e.PageErrors)
+	slices.Sort(pages)
+	return pages
+}
+
+// Events gets events from a number of event pages of a portal. If portal is nil, the default
+// portal is used. If pages is nil, only the first page is fetched. If only some pages could
+// not be fetched, the events of the other pages are returned along with a PartialError.
+func (c *Client) Events(
+	ctx context.Context, portal *Portal, location *string, radius *int, pages *int,
+) ([]Event, error) {
+	eventPages, err := c.manyPageEvents(ctx, c.portalOrDefault(portal), location, radius, pages)
+	c.recordScrape(ctx, err)
+
+	var partialErr *PartialError
+	if err != nil && !errors.As(err, &partialErr) {
 		return nil, err
 	}
 
@@ -65,12 +96,12 @@ func Events(ctx context.Context, location *string, pages *int) ([]Event, error)
 		}
 	}
 
-	return events, nil
+	return events, err
 }
 
-func pageEvents(ctx context.Context, input EventsInput) ([]Event, error) {
+func (c *Client) pageEvents(ctx context.Context, input EventsInput) ([]Event, error) {
 	// Get events page
-	eventsPage, err := getEventsPage(ctx, input)
+	eventsPage, err := c.getEventsPage(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -79,26 +110,48 @@ func pageEvents(ctx context.Context, input EventsInput) ([]Event, error) {
 	var t4g T4G
 	err = NewHTMLParser().Parse(&t4g, eventsPage)
 	if err != nil {
+		c.metrics.parseFailures.WithLabelValues(parseFailureEventsPage).Inc()
 		return nil, err
 	}
+	c.metrics.pageEventCount.Observe(float64(len(t4g.Events)))
 
-	// Sanitise events
+	// Sanitise events and parse their dates
+	portal := c.portalOrDefault(input.Portal)
 	events := make([]Event, 0, len(t4g.Events))
 	for _, event := range t4g.Events {
-		events = append(events, event.sanitise())
+		// Drop events without an id, as they have no link and cannot be told apart
+		if event.Id == 0 {
+			c.metrics.parseFailures.WithLabelValues(parseFailureEventCard).Inc()
+			c.logger.WarnContext(ctx, "Dropping event without an id", "title", event.Title)
+			continue
+		}
+
+		event = event.sanitise(portal)
+
+		// Report, but do not drop, events with dates that cannot be parsed.
+		// The raw date string will still be available.
+		event.EventDates, err = ParseEventDates(event.Date, c.now())
+		if err != nil {
+			c.metrics.parseFailures.WithLabelValues(parseFailureEventDate).Inc()
+			c.logger.WarnContext(ctx, "Failed to parse event date", "eventId", event.Id, "error", err)
+		}
+
+		events = append(events, event)
 	}
 
 	return events, nil
 }
 
-func manyPageEvents(ctx context.Context, location *string, numPages *int) ([][]Event, error) {
+func (c *Client) manyPageEvents(
+	ctx context.Context, portal Portal, location *string, radius *int, numPages *int,
+) ([][]Event, error) {
 	pages := lo.FromPtr(numPages)
 	if pages < 1 {
 		pages = 1
 	}
 
 	eventPages := make([][]Event, pages)
-	var errs error
+	pageErrors := map[int]error{}
 
 	var wg sync.WaitGroup
 	var eventsMutex sync.Mutex
@@ -110,15 +163,18 @@ func manyPageEvents(ctx context.Context, location *string, numPages *int) ([][]E
 		go func(idx int) {
 			defer wg.Done()
 
-			pageEvents, err := pageEvents(
+			page := idx + 1
+			pageEvents, err := c.pageEvents(
 				ctx, EventsInput{
+					Portal:   &portal,
 					Location: location,
-					Page:     lo.ToPtr(idx + 1),
+					Radius:   radius,
+					Page:     lo.ToPtr(page),
 				},
 			)
 			if err != nil {
 				errsMutex.Lock()
-				errs = errors.Join(errs, err)
+				pageErrors[page] = err
 				errsMutex.Unlock()
 				return
 			}
@@ -130,5 +186,26 @@ func manyPageEvents(ctx context.Context, location *string, numPages *int) ([][]E
 	}
 	wg.Wait()
 
-	return eventPages, errs
+	switch {
+	case len(pageErrors) == 0:
+		return eventPages, nil
+	case len(pageErrors) == pages:
+		return nil, joinPageErrors(pageErrors)
+	default:
+		c.metrics.failedEventPages.Add(float64(len(pageErrors)))
+		return eventPages, &PartialError{PageErrors: pageErrors}
+	}
+}
+
+// joinPageErrors joins the errors of event pages, in page order
+func joinPageErrors(pageErrors map[int]error) error {
+	pages := lo.Keys(pageErrors)
+	slices.Sort(pages)
+
+	errs := make([]error, 0, len(pages))
+	for _, page := range pages {
+		errs = append(errs, fmt.Errorf("page %d: %w", page, pageErrors[page]))
+	}
+
+	return errors.Join(errs...)
 }
@@ -2,29 +2,127 @@ package t4g_test
 
 import (
 	"context"
-	"net/url"
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 )
 
+func eventIds(events []t4g.Event) []int {
+	return lo.Map(events, func(event t4g.Event, _ int) int { return event.Id })
+}
+
 func TestT4GEvents(t *testing.T) {
-	events, err := t4g.Events(context.Background(), nil, nil)
+	server := t4gtest.NewServer(t)
+	client := server.Client(t)
+
+	events, err := client.Events(context.Background(), nil, nil, nil, nil)
 	require.NoError(t, err)
-	require.Len(t, events, 12)
+	require.Len(t, events, t4gtest.Page1Events)
 
-	require.NotEmpty(t, events[0].Id)
-	require.NotEmpty(t, events[0].Title)
-	require.NotEmpty(t, events[0].Image)
-	require.NotEmpty(t, events[0].Link)
-	require.NotEmpty(t, events[0].Location)
-	require.NotEmpty(t, events[0].Date)
-	require.NotEmpty(t, events[0].Category)
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	require.Equal(
+		t,
+		t4g.Event{
+			Id:       t4gtest.FirstEventId,
+			Title:    "Hamilton",
+			Image:    "https://cdn.ticketsforgood.co.uk/events/40212/image.jpg",
+			Link:     server.URL + "/events/40212",
+			Location: "Victoria Palace Theatre, London",
+			Date:     "Sat 20th Jan 2024 7:30pm",
+			Category: "Theatre",
+			EventDates: t4g.EventDates{
+				StartsAt: time.Date(2024, 1, 20, 19, 30, 0, 0, london),
+			},
+		},
+		events[0],
+	)
+
+	// Every event should have been parsed
+	for _, event := range events {
+		require.NotEmpty(t, event.Id)
+		require.NotEmpty(t, event.Title)
+		require.NotEmpty(t, event.Image)
+		require.NotEmpty(t, event.Link)
+		require.NotEmpty(t, event.Location)
+		require.NotEmpty(t, event.Date)
+		require.NotEmpty(t, event.Category)
+	}
+}
 
-	_, err = url.Parse(events[0].Image)
+func TestT4GEventsPagination(t *testing.T) {
+	client := t4gtest.NewServer(t).Client(t)
+
+	events, err := client.Events(context.Background(), nil, lo.ToPtr("pagination"), nil, lo.ToPtr(3))
 	require.NoError(t, err)
 
-	_, err = url.Parse(events[0].Link)
+	// The repeated event and the event without a link should be dropped,
+	// and the third page is empty
+	require.Len(t, events, t4gtest.Page1Events+t4gtest.Page2Events)
+	require.Len(t, lo.Uniq(eventIds(events)), len(events))
+	require.Equal(t, t4gtest.FirstEventId, events[0].Id)
+	require.Equal(t, t4gtest.MalformedEventId, events[len(events)-1].Id)
+}
+
+func TestT4GEventsMalformedCard(t *testing.T) {
+	client := t4gtest.NewServer(t).Client(t)
+
+	events, err := client.Events(context.Background(), nil, lo.ToPtr("malformed"), nil, lo.ToPtr(2))
 	require.NoError(t, err)
+
+	// Events with missing fields are kept, with whatever could be parsed
+	event, ok := lo.Find(events, func(event t4g.Event) bool { return event.Id == t4gtest.MalformedEventId })
+	require.True(t, ok)
+	require.Equal(t, "Mystery Event", event.Title)
+	require.Equal(t, "Coming soon", event.Date)
+	require.Empty(t, event.Image)
+	require.Empty(t, event.Category)
+	require.True(t, event.StartsAt.IsZero())
+
+	require.False(t, lo.ContainsBy(events, func(event t4g.Event) bool { return event.Title == "Event Without A Link" }))
+}
+
+func TestT4GEventsEmptyPage(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	server.SetPageStatus(1, http.StatusNotFound)
+
+	events, err := server.Client(t).Events(context.Background(), nil, lo.ToPtr("empty"), nil, lo.ToPtr(4))
+
+	var partialErr *t4g.PartialError
+	require.ErrorAs(t, err, &partialErr)
+	require.Equal(t, []int{1}, partialErr.FailedPages())
+
+	// Only page 2 has events, as pages 3 and 4 are empty
+	require.Len(t, events, t4gtest.Page2Events+1)
+}
+
+func TestT4GEventsErrorStatus(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	server.SetPageStatus(1, http.StatusInternalServerError)
+	server.SetPageStatus(2, http.StatusTooManyRequests)
+
+	_, err := server.Client(t).Events(context.Background(), nil, lo.ToPtr("error"), nil, lo.ToPtr(2))
+	require.Error(t, err)
+
+	var partialErr *t4g.PartialError
+	require.False(t, errors.As(err, &partialErr), "error should not be partial when every page fails")
+}
+
+func TestEventsPartialResults(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	server.SetPageStatus(2, http.StatusInternalServerError)
+
+	events, err := server.Client(t).Events(context.Background(), nil, lo.ToPtr("partial"), nil, lo.ToPtr(3))
+
+	var partialErr *t4g.PartialError
+	require.ErrorAs(t, err, &partialErr)
+	require.Equal(t, []int{2}, partialErr.FailedPages())
+	require.Len(t, events, t4gtest.Page1Events)
 }
@@ -2,8 +2,10 @@ package t4g
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,29 +17,241 @@ import (
 	mapset "github.com/deckarep/golang-set/v2"
 )
 
+// FeedInput is the input used to create a feed.
+// Feeds are cached by their input, so feeds with different inputs are independent.
+type FeedInput struct {
+	Portal   *Portal // Portal to get events from. If nil, the default portal is used.
+	Location *string
+	Radius   *int // Search radius in miles
+	Filter   EventFilter
+}
+
+// Key returns a key that uniquely identifies the feed created from the input. Feeds of the
+// NHS portal, or without a portal, have no portal in their key, so keys from before portals
+// could be chosen still identify the same feeds. Use Client.FeedKey to get the key of a feed
+// of a client, as this takes the default portal of the client into account.
+func (i FeedInput) Key() string {
+	key := fmt.Sprintf(
+		"%s|%d|%s",
+		strings.ToLower(lo.FromPtr(i.Location)), radiusOrDefault(i.Radius), i.Filter.key(),
+	)
+
+	if i.Portal != nil && i.Portal.Name != DefaultPortalName {
+		key = fmt.Sprintf("%s|%s", key, i.Portal.Name)
+	}
+
+	return key
+}
+
 type Feed struct {
-	location *string
-	maxItems int
-	feed     *feeds.Feed
-	mutex    sync.Mutex
+	client      *Client
+	input       FeedInput
+	maxItems    int
+	feed        *feeds.Feed
+	events      map[string]Event // Feed item id -> event
+	refreshedAt time.Time
+	requestedAt time.Time
+	failedAt    time.Time // Time the last update failed. Zero if it succeeded.
+	failErr     error     // Error of the last update, if it failed
+	mutex       sync.Mutex
+
+	// updateMutex ensures only one update of the feed happens at a time.
+	// It is separate to mutex so the feed can still be read during an update.
+	updateMutex sync.Mutex
 }
 
+// UpdatedAt returns the time the feed items last changed
 func (f *Feed) UpdatedAt() time.Time {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 	return f.feed.Updated
 }
 
-func (f *Feed) Update(ctx context.Context, numEventPages int) error {
+// RefreshedAt returns the time the feed was last refreshed with events,
+// regardless of whether any of its items changed
+func (f *Feed) RefreshedAt() time.Time {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
+	return f.refreshedAt
+}
 
-	// Get events
-	events, err := Events(ctx, f.location, lo.ToPtr(numEventPages))
-	if err != nil {
+// Title returns the title of the feed
+func (f *Feed) Title() string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.feed.Title
+}
+
+// Link returns the url of the events of the feed on Tickets For Good
+func (f *Feed) Link() string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.feed.Link.Href
+}
+
+// RequestedAt returns the time the feed was last requested
+func (f *Feed) RequestedAt() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.requestedAt
+}
+
+// lastFailure returns the time and error of the last update if it failed
+func (f *Feed) lastFailure() (time.Time, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.failedAt, f.failErr
+}
+
+func (f *Feed) markRequested() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requestedAt = f.client.now()
+}
+
+// Update updates the feed with new events from a number of event pages.
+// If only some pages could not be fetched, the feed is still updated with the
+// events of the other pages and a PartialError is returned.
+func (f *Feed) Update(ctx context.Context, numEventPages int) error {
+	f.updateMutex.Lock()
+	defer f.updateMutex.Unlock()
+
+	// Get events. Continue with partial results if some pages failed.
+	events, err := f.client.Events(ctx, f.input.Portal, f.input.Location, f.input.Radius, lo.ToPtr(numEventPages))
+	var partialErr *PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		// Record failures, unless the update was cancelled as that says nothing about upstream
+		if ctx.Err() == nil {
+			f.mutex.Lock()
+			f.failedAt = f.client.now()
+			f.failErr = err
+			f.mutex.Unlock()
+		}
 		return err
 	}
 
+	// Filter events
+	events = f.input.Filter.Apply(events)
+
+	// Get details of new events to use as feed item content. Details of events
+	// that could not be fetched in previous updates are fetched again.
+	newEvents := f.newEvents(events)
+	eventDetails := f.client.FetchEventDetails(ctx, append(f.eventsWithoutContent(), newEvents...))
+
+	f.mutex.Lock()
+
+	// Listeners are notified of new events, unless this is the first time
+	// the feed has been populated as then every event is new
+	notifyListeners := len(f.feed.Items) != 0 && len(newEvents) != 0
+
+	contentAdded := f.addMissingContent(ctx, eventDetails)
+	for _, event := range newEvents {
+		feedItem := f.client.eventToFeedItem(ctx, event, eventDetails[event.Id])
+		f.feed.Add(feedItem)
+		if feedItem.Id != "" {
+			f.events[feedItem.Id] = event
+		}
+	}
+
+	f.sortAndTrimItems()
+	f.client.metrics.feedUpdateNewItems.Observe(float64(len(newEvents)))
+
+	// Update the refreshed time, and the updated time if items have changed.
+	// Keeping the updated time the same when nothing has changed means the
+	// feed content stays the same, allowing readers to cache it.
+	f.refreshedAt = f.client.now()
+	f.failedAt = time.Time{}
+	f.failErr = nil
+	if len(newEvents) != 0 || contentAdded || f.feed.Updated.IsZero() {
+		f.feed.Updated = f.refreshedAt
+	}
+	f.mutex.Unlock()
+
+	// Notify listeners without holding the lock, so slow listeners do not stop the feed being read
+	if notifyListeners {
+		f.client.notifyNewEventsListeners(ctx, f.input, newEvents)
+	}
+
+	return err
+}
+
+// eventsWithoutContent returns the events of feed items without content,
+// which are the events whose details could not be fetched
+func (f *Feed) eventsWithoutContent() []Event {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var events []Event
+	for _, item := range f.feed.Items {
+		if event, ok := f.events[item.Id]; ok && item.Content == "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// addMissingContent sets the content of feed items without content from event details,
+// returning whether the content of any item was set. The feed mutex must be held.
+func (f *Feed) addMissingContent(ctx context.Context, eventDetails map[int]*EventDetail) bool {
+	var contentAdded bool
+	for _, item := range f.feed.Items {
+		event, ok := f.events[item.Id]
+		if !ok || item.Content != "" {
+			continue
+		}
+
+		item.Content = f.client.eventContent(ctx, event, eventDetails[event.Id])
+		contentAdded = contentAdded || item.Content != ""
+	}
+	return contentAdded
+}
+
+// sortAndTrimItems sorts the feed items and removes the oldest
+// items (and their events) so the feed is within its maximum size
+func (f *Feed) sortAndTrimItems() {
+	// Sort feed items
+	f.feed.Sort(feedSortFunc)
+
+	// Keep length of feed to maximum number of items
+	if len(f.feed.Items) > f.maxItems {
+		f.feed.Items = f.feed.Items[:f.maxItems]
+	}
+
+	// Remove events of items no longer in the feed
+	itemIds := mapset.NewSetWithSize[string](len(f.feed.Items))
+	for _, item := range f.feed.Items {
+		itemIds.Add(item.Id)
+	}
+	for itemId := range f.events {
+		if !itemIds.Contains(itemId) {
+			delete(f.events, itemId)
+		}
+	}
+}
+
+// Events returns the events in the feed, in the same order as the feed items.
+// Items without a known event are skipped.
+func (f *Feed) Events() []Event {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.orderedEvents()
+}
+
+func (f *Feed) orderedEvents() []Event {
+	events := make([]Event, 0, len(f.events))
+	for _, item := range f.feed.Items {
+		if event, ok := f.events[item.Id]; ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// newEvents returns the events that should be added to the feed
+func (f *Feed) newEvents(events []Event) []Event {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	// Get current feed ids, ignoring ones that cannot be converted to a number
 	// Items without a number will be sorted to the end and eventually removed
 	feedIds := mapset.NewSetWithSize[int](len(f.feed.Items))
@@ -57,25 +271,36 @@ func (f *Feed) Update(ctx context.Context, numEventPages int) error {
 	// and are larger than the current min feed id as
 	// sometimes events are removed and older events
 	// creep back in.
-	for _, event := range events {
-		if !feedIds.Contains(event.Id) && event.Id > minFeedId {
-			feedItem := eventToFeedItem(event)
-			f.feed.Add(feedItem)
-		}
-	}
-
-	// Sort feed items
-	f.feed.Sort(feedSortFunc)
+	return lo.Filter(events, func(event Event, _ int) bool {
+		return !feedIds.Contains(event.Id) && event.Id > minFeedId
+	})
+}
 
-	// Keep length of feed to maximum number of items
-	if len(f.feed.Items) > f.maxItems {
-		f.feed.Items = f.feed.Items[:f.maxItems]
-	}
+// Formats a feed can be rendered in
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+	FormatICS  = "ics"
+)
 
-	// Update the updated time
-	f.feed.Updated = time.Now()
+// FeedFormats are the formats a feed can be rendered in
+var FeedFormats = []string{FormatRSS, FormatAtom, FormatJSON, FormatICS}
 
-	return nil
+// Render renders the feed in one of FeedFormats
+func (f *Feed) Render(format string) (string, error) {
+	switch format {
+	case FormatRSS:
+		return f.ToRss()
+	case FormatAtom:
+		return f.ToAtom()
+	case FormatJSON:
+		return f.ToJSON()
+	case FormatICS:
+		return f.ToICS()
+	default:
+		return "", fmt.Errorf("unknown feed format %q", format)
+	}
 }
 
 func (f *Feed) ToRss() (string, error) {
@@ -84,32 +309,125 @@ func (f *Feed) ToRss() (string, error) {
 	return f.feed.ToRss()
 }
 
-func NewFeed(location *string, maxSize *int) *Feed {
+func (f *Feed) ToAtom() (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.feed.ToAtom()
+}
+
+func (f *Feed) ToJSON() (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.feed.ToJSON()
+}
+
+// Snapshot returns a snapshot of the current state of the feed
+func (f *Feed) Snapshot() *FeedSnapshot {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := make([]*feeds.Item, 0, len(f.feed.Items))
+	for _, item := range f.feed.Items {
+		itemCopy := *item
+		items = append(items, &itemCopy)
+	}
+
+	return &FeedSnapshot{
+		Updated:   f.feed.Updated,
+		Refreshed: f.refreshedAt,
+		Items:     items,
+		Events:    f.orderedEvents(),
+	}
+}
+
+// Restore restores the state of the feed from a snapshot
+func (f *Feed) Restore(snapshot *FeedSnapshot) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.feed.Updated = snapshot.Updated
+	f.refreshedAt = snapshot.Refreshed
+	f.feed.Items = snapshot.Items
+	f.events = make(map[string]Event, len(snapshot.Events))
+	for _, event := range snapshot.Events {
+		f.events[strconv.Itoa(event.Id)] = event
+	}
+	f.sortAndTrimItems()
+}
+
+// NewFeed creates an empty feed of the client for an input. Feeds created with NewFeed are not
+// cached, so are not shared with FetchFeed or refreshed in the background.
+func (c *Client) NewFeed(input FeedInput) *Feed {
+	input = c.resolveFeedInput(input)
+	portal := *input.Portal
+
 	feedTitle := "T4G Feed"
 	feedDescription := "Tickets For Good Events"
-	if location != nil {
-		titleLocation := cases.Title(language.English).String(*location)
+	if portal.Name != DefaultPortalName {
+		feedDescription = fmt.Sprintf("%s on the %s portal", feedDescription, portal.Name)
+	}
+	if input.Location != nil {
+		titleLocation := cases.Title(language.English).String(*input.Location)
 
 		feedTitle = fmt.Sprintf("%s: %s", feedTitle, titleLocation)
-		feedDescription = fmt.Sprintf("%s in %s", feedDescription, titleLocation)
+		feedDescription = fmt.Sprintf(
+			"%s within %d miles of %s",
+			feedDescription, radiusOrDefault(input.Radius), titleLocation,
+		)
 	}
 
-	maxFeedItems := 100
-	if maxSize != nil {
-		maxFeedItems = *maxSize
+	if portal.Name != DefaultPortalName {
+		feedTitle = fmt.Sprintf("%s [%s]", feedTitle, portal.Name)
+	}
+	if filterTitle := input.Filter.Title(); filterTitle != "" {
+		feedTitle = fmt.Sprintf("%s (%s)", feedTitle, filterTitle)
+	}
+	if filterDescription := input.Filter.Description(); filterDescription != "" {
+		feedDescription = fmt.Sprintf("%s %s", feedDescription, filterDescription)
 	}
 
 	return &Feed{
-		location: location,
-		maxItems: maxFeedItems,
+		client:   c,
+		input:    input,
+		maxItems: c.maxFeedItems,
+		events:   map[string]Event{},
 		feed: &feeds.Feed{
-			Title:       feedTitle,
-			Link:        &feeds.Link{Href: EventsUrl(EventsInput{Location: location})},
+			Title: feedTitle,
+			Link: &feeds.Link{
+				Href: c.EventsUrl(EventsInput{Portal: input.Portal, Location: input.Location, Radius: input.Radius}),
+			},
 			Description: feedDescription,
 		},
 	}
 }
 
+// FeedKey returns a key that uniquely identifies a feed of the client created from an input
+func (c *Client) FeedKey(input FeedInput) string {
+	return c.resolveFeedInput(input).Key()
+}
+
+// resolveFeedInput sets the portal of an input to the default portal if it is not set,
+// so feeds keep using the same portal, and feeds of the same portal have the same key.
+// The input is copied, so changes to the input by the caller do not change feeds.
+func (c *Client) resolveFeedInput(input FeedInput) FeedInput {
+	portal := c.portalOrDefault(input.Portal)
+	return FeedInput{
+		Portal:   &portal,
+		Location: clonePtr(input.Location),
+		Radius:   clonePtr(input.Radius),
+		Filter:   input.Filter.clone(),
+	}
+}
+
+// clonePtr returns a pointer to a copy of the value a pointer points to, or nil if the pointer is nil
+func clonePtr[T any](ptr *T) *T {
+	if ptr == nil {
+		return nil
+	}
+	value := *ptr
+	return &value
+}
+
 func feedSortFunc(item1, item2 *feeds.Item) bool {
 	feedId1, err := strconv.Atoi(item1.Id)
 	if err != nil {
@@ -0,0 +1,154 @@
+package t4g
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// EventFilter is a filter that can be applied to events.
+// An empty filter matches all events.
+type EventFilter struct {
+	Categories        []string       // Only include events in one of these categories
+	ExcludeCategories []string       // Exclude events in any of these categories
+	Query             *string        // Only include events whose title or location contains this (case-insensitive)
+	TitleRegex        *regexp.Regexp // Only include events whose title matches this regex
+}
+
+// clone returns a copy of the filter that does not share slices or pointers with it.
+// The title regex is shared, as regexes cannot be changed once compiled.
+func (f EventFilter) clone() EventFilter {
+	return EventFilter{
+		Categories:        slices.Clone(f.Categories),
+		ExcludeCategories: slices.Clone(f.ExcludeCategories),
+		Query:             clonePtr(f.Query),
+		TitleRegex:        f.TitleRegex,
+	}
+}
+
+// Match returns whether an event matches the filter
+func (f EventFilter) Match(event Event) bool {
+	if len(f.Categories) != 0 && !containsFold(f.Categories, event.Category) {
+		return false
+	}
+
+	if containsFold(f.ExcludeCategories, event.Category) {
+		return false
+	}
+
+	if query := strings.TrimSpace(lo.FromPtr(f.Query)); query != "" &&
+		!containsFoldSubstring(event.Title, query) &&
+		!containsFoldSubstring(event.Location, query) {
+		return false
+	}
+
+	if f.TitleRegex != nil && !f.TitleRegex.MatchString(event.Title) {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns the events that match the filter
+func (f EventFilter) Apply(events []Event) []Event {
+	if f.isEmpty() {
+		return events
+	}
+
+	return lo.Filter(events, func(event Event, _ int) bool { return f.Match(event) })
+}
+
+// Title returns a short title describing the filter, or an empty string if the filter is empty
+func (f EventFilter) Title() string {
+	var titleParts []string
+	if len(f.Categories) != 0 {
+		titleParts = append(titleParts, strings.Join(f.Categories, ", "))
+	}
+	if len(f.ExcludeCategories) != 0 {
+		titleParts = append(titleParts, fmt.Sprintf("excluding %s", strings.Join(f.ExcludeCategories, ", ")))
+	}
+	if query := strings.TrimSpace(lo.FromPtr(f.Query)); query != "" {
+		titleParts = append(titleParts, fmt.Sprintf("%q", query))
+	}
+	if f.TitleRegex != nil {
+		titleParts = append(titleParts, fmt.Sprintf("/%s/", f.TitleRegex))
+	}
+
+	return strings.Join(titleParts, " ")
+}
+
+// Description returns a description of the filter, or an empty string if the filter is empty
+func (f EventFilter) Description() string {
+	var descriptionParts []string
+	if len(f.Categories) != 0 {
+		descriptionParts = append(
+			descriptionParts,
+			fmt.Sprintf("in categories %s", strings.Join(f.Categories, ", ")),
+		)
+	}
+	if len(f.ExcludeCategories) != 0 {
+		descriptionParts = append(
+			descriptionParts,
+			fmt.Sprintf("excluding categories %s", strings.Join(f.ExcludeCategories, ", ")),
+		)
+	}
+	if query := strings.TrimSpace(lo.FromPtr(f.Query)); query != "" {
+		descriptionParts = append(descriptionParts, fmt.Sprintf("matching %q", query))
+	}
+	if f.TitleRegex != nil {
+		descriptionParts = append(descriptionParts, fmt.Sprintf("with titles matching /%s/", f.TitleRegex))
+	}
+
+	return strings.Join(descriptionParts, " ")
+}
+
+func (f EventFilter) isEmpty() bool {
+	return len(f.Categories) == 0 &&
+		len(f.ExcludeCategories) == 0 &&
+		strings.TrimSpace(lo.FromPtr(f.Query)) == "" &&
+		f.TitleRegex == nil
+}
+
+// key returns a key that uniquely identifies the filter.
+// Filters that match the same events have the same key.
+func (f EventFilter) key() string {
+	var titleRegex string
+	if f.TitleRegex != nil {
+		titleRegex = f.TitleRegex.String()
+	}
+
+	return fmt.Sprintf(
+		"%s|%s|%s|%s",
+		normalisedKey(f.Categories),
+		normalisedKey(f.ExcludeCategories),
+		strings.ToLower(strings.TrimSpace(lo.FromPtr(f.Query))),
+		titleRegex,
+	)
+}
+
+// normalisedKey returns a case-insensitive, order-independent key for a list of values
+func normalisedKey(values []string) string {
+	normalisedValues := make([]string, 0, len(values))
+	for _, value := range values {
+		normalisedValues = append(normalisedValues, strings.ToLower(strings.TrimSpace(value)))
+	}
+	sort.Strings(normalisedValues)
+	return strings.Join(lo.Uniq(normalisedValues), ",")
+}
+
+// containsFold returns whether values contains a value, ignoring case and surrounding whitespace
+func containsFold(values []string, value string) bool {
+	value = strings.TrimSpace(value)
+	return lo.ContainsBy(values, func(item string) bool {
+		return strings.EqualFold(strings.TrimSpace(item), value)
+	})
+}
+
+// containsFoldSubstring returns whether s contains substr, ignoring case
+func containsFoldSubstring(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
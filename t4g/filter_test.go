@@ -0,0 +1,56 @@
+package t4g_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilterCategories(t *testing.T) {
+	events := []t4g.Event{
+		{Id: 1, Category: "Theatre"},
+		{Id: 2, Category: "Comedy"},
+		{Id: 3, Category: "Sport"},
+		{Id: 4, Category: "Music"},
+	}
+
+	filter := t4g.EventFilter{Categories: []string{"theatre", "COMEDY"}}
+	require.Equal(t, []t4g.Event{events[0], events[1]}, filter.Apply(events))
+
+	filter = t4g.EventFilter{ExcludeCategories: []string{"sport"}}
+	require.Equal(t, []t4g.Event{events[0], events[1], events[3]}, filter.Apply(events))
+
+	filter = t4g.EventFilter{
+		Categories:        []string{"Theatre", "Sport"},
+		ExcludeCategories: []string{"Sport"},
+	}
+	require.Equal(t, []t4g.Event{events[0]}, filter.Apply(events))
+
+	require.Equal(t, events, t4g.EventFilter{}.Apply(events))
+}
+
+func TestEventFilterQueryAndTitleRegex(t *testing.T) {
+	events := []t4g.Event{
+		{Id: 1, Title: "Coldplay Live", Location: "Wembley Stadium"},
+		{Id: 2, Title: "Hamlet", Location: "The Globe"},
+		{Id: 3, Title: "Stand Up Night", Location: "Leeds Town Hall"},
+	}
+
+	filter := t4g.EventFilter{Query: lo.ToPtr("coldplay")}
+	require.Equal(t, []t4g.Event{events[0]}, filter.Apply(events))
+
+	filter = t4g.EventFilter{Query: lo.ToPtr("GLOBE")}
+	require.Equal(t, []t4g.Event{events[1]}, filter.Apply(events))
+
+	filter = t4g.EventFilter{TitleRegex: regexp.MustCompile(`(?i)^(hamlet|stand up)`)}
+	require.Equal(t, []t4g.Event{events[1], events[2]}, filter.Apply(events))
+
+	filter = t4g.EventFilter{
+		Query:      lo.ToPtr("l"),
+		TitleRegex: regexp.MustCompile(`Live$`),
+	}
+	require.Equal(t, []t4g.Event{events[0]}, filter.Apply(events))
+}
@@ -2,40 +2,29 @@ package t4g
 
 import (
 	"context"
-	"io"
-	"log"
-	"net/http"
-	"net/url"
 	"strconv"
-
-	"github.com/ahobsonsayers/t4g-feed/utils"
 )
 
-const TicketsForGoodURL = "https://nhs.ticketsforgood.co.uk"
-
-var ticketsForGoodUrl *url.URL
+const (
+	TicketsForGoodURL = "https://nhs.ticketsforgood.co.uk" // Base url of the default portal
+	DefaultRadius     = 30                                 // Default search radius in miles
+)
 
 type EventsInput struct {
+	Portal   *Portal // Portal to get events from. If nil, the default portal is used.
 	Location *string
+	Radius   *int // Search radius in miles
 	Page     *int
 }
 
-func init() {
-	var err error
-	ticketsForGoodUrl, err = url.Parse(TicketsForGoodURL)
-	if err != nil {
-		log.Fatal("failed to parse tickets for good url")
-	}
-}
-
-func EventsUrl(input EventsInput) string {
-	ticketsForGoodEventsUrl := utils.CloneURL(ticketsForGoodUrl)
-	ticketsForGoodEventsUrl.Path = "events"
+// EventsUrl returns the url of a page of events on Tickets For Good
+func (c *Client) EventsUrl(input EventsInput) string {
+	ticketsForGoodEventsUrl := c.portalOrDefault(input.Portal).URL.JoinPath("events")
 
 	// Set query params
 	queryParams := ticketsForGoodEventsUrl.Query()
 	queryParams.Set("sort", "newest")
-	queryParams.Set("range", "30")
+	queryParams.Set("range", strconv.Itoa(radiusOrDefault(input.Radius)))
 	if input.Location != nil && *input.Location != "" {
 		queryParams.Set("location", *input.Location)
 	}
@@ -47,28 +36,18 @@ func EventsUrl(input EventsInput) string {
 	return ticketsForGoodEventsUrl.String()
 }
 
-func getEventsPage(ctx context.Context, input EventsInput) (string, error) {
-	// Get page
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, EventsUrl(input), http.NoBody)
-	if err != nil {
-		return "", err
-	}
-
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return "", err
-	}
-	defer response.Body.Close()
+func (c *Client) getEventsPage(ctx context.Context, input EventsInput) (string, error) {
+	return c.getPage(ctx, c.EventsUrl(input))
+}
 
-	httpError := utils.HTTPResponseError(response)
-	if httpError != nil {
-		return "", httpError
-	}
+func (c *Client) getPage(ctx context.Context, pageUrl string) (string, error) {
+	return c.upstream.GetPage(ctx, pageUrl)
+}
 
-	bodyBytes, err := io.ReadAll(response.Body)
-	if err != nil {
-		return "", err
+// radiusOrDefault returns the radius if it is set and valid, otherwise the default radius
+func radiusOrDefault(radius *int) int {
+	if radius == nil || *radius < 1 {
+		return DefaultRadius
 	}
-
-	return string(bodyBytes), nil
+	return *radius
 }
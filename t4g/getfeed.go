@@ -2,81 +2,335 @@ package t4g
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/gorilla/feeds"
 	"github.com/samber/lo"
 )
 
-const (
-	maxFeedItems  = 75
-	numEventPages = 5 // Number of event pages to get on update
-)
+// FailedRefreshRetryInterval is the time after a feed fails to refresh before it is refreshed again on request.
+// Until then, the stale feed is returned without waiting for a refresh that is likely to fail.
+const FailedRefreshRetryInterval = time.Minute
 
-var (
-	cachedFeeds      = map[string]*Feed{} // Location -> feed
-	cachedFeedsMutex sync.Mutex
-)
+// feedRefresh is an in progress refresh of a feed, shared by everything waiting for the feed to be refreshed
+type feedRefresh struct {
+	done    chan struct{} // Closed when the refresh has finished
+	err     error         // Error of the refresh. Only set once done is closed.
+	waiters int           // Number of callers waiting for the refresh
+	cancel  context.CancelFunc
+}
+
+// StaleFeedError is returned by FetchFeed when a feed could not be refreshed, but has been fetched
+// before. The stale feed is returned along with the error, so it can still be served.
+type StaleFeedError struct {
+	Err         error
+	RefreshedAt time.Time // Time the stale feed was last refreshed
+}
+
+func (e *StaleFeedError) Error() string {
+	return fmt.Sprintf("feed is stale, last refreshed at %s: %s", e.RefreshedAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *StaleFeedError) Unwrap() error {
+	return e.Err
+}
 
-// FetchFeed will fetch a Tickets For Good events feed for a location.
+// FetchFeed will fetch a Tickets For Good events feed for an input (location, radius etc.).
 // If debounce time is set and the time period has not passed since the time the
-// feed was last updated and the function call, a cached feed will be returned.
-// If the time period has pass, the feed will be fully retched.
-func FetchFeed(ctx context.Context, location *string, debounceTime *time.Duration) (*Feed, error) {
-	cachedFeedsMutex.Lock()
-	defer cachedFeedsMutex.Unlock()
-
-	// Get cached feed. If there is no cached feed, create a new one.
-	// Only keep the 10 most recently updated feeds
-	feed, isCached := cachedFeeds[lo.FromPtr(location)]
+// feed was last refreshed and the function call, a cached feed will be returned.
+// If the time period has pass, the feed will be fully retched, unless background
+// refresh has been started (see StartBackgroundRefresh).
+//
+// If only some event pages could not be fetched, the updated feed is returned along with a PartialError.
+// If the feed could not be refreshed but has been fetched before, the stale feed is returned along
+// with a StaleFeedError. Refreshes are not retried on request within FailedRefreshRetryInterval.
+//
+// Concurrent fetches of the same feed share a single refresh. Fetches of different feeds are independent.
+func (c *Client) FetchFeed(ctx context.Context, input FeedInput, debounceTime *time.Duration) (*Feed, error) {
+	input = c.resolveFeedInput(input)
+	feedKey := input.Key()
+	feed := c.cachedFeed(feedKey, input)
+
+	// If there is a debounce and we are within the debounce period, return the cached feed
+	isDebounced := debounceTime != nil && c.since(feed.RefreshedAt()) < *debounceTime
+	if isDebounced {
+		c.metrics.feedCacheHits.Inc()
+		return feed, nil
+	}
+
+	// If the last refresh failed recently, do not try again yet
+	failedAt, failErr := feed.lastFailure()
+	if failErr != nil && c.since(failedAt) < FailedRefreshRetryInterval {
+		c.metrics.feedCacheHits.Inc()
+		return staleFeed(feed, failErr)
+	}
+
+	// If feeds are being refreshed in the background, return the cached feed immediately
+	// (if it has been fetched before) and refresh it in the background
+	refresher := c.getBackgroundRefresher()
+	if refresher != nil && !feed.RefreshedAt().IsZero() {
+		refresher.refreshAfter(feedKey, feed, 0)
+		c.metrics.feedCacheHits.Inc()
+		if failErr != nil {
+			return staleFeed(feed, failErr)
+		}
+		return feed, nil
+	}
+
+	// Refresh feed with event pages. If only some pages failed,
+	// the feed has still been updated so is returned with the error.
+	c.metrics.feedCacheMisses.Inc()
+	err := c.refreshFeed(ctx, feedKey, feed)
+	var partialErr *PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		return staleFeed(feed, err)
+	}
+
+	return feed, err
+}
+
+// cachedFeed gets the cached feed of an input, marking it as requested. If there is no
+// cached feed, a new one is created (restored from the feed store if possible) and cached.
+// If there are too many cached feeds, the least recently requested feed is removed, along
+// with its snapshot in the feed store, so the store does not grow with every feed requested.
+func (c *Client) cachedFeed(key string, input FeedInput) *Feed {
+	c.cachedFeedsMutex.Lock()
+	feed, isCached := c.cachedFeeds[key]
+	if isCached {
+		feed.markRequested()
+	}
+	c.cachedFeedsMutex.Unlock()
+
+	if isCached {
+		return feed
+	}
+
+	// Create the feed without holding the lock, as loading it can be slow
+	newFeed := c.NewFeed(input)
+	c.loadFeed(key, newFeed)
+
+	c.cachedFeedsMutex.Lock()
+
+	// Another fetch may have cached the feed while it was being created
+	feed, isCached = c.cachedFeeds[key]
 	if !isCached {
-		feed = NewFeed(location, lo.ToPtr(maxFeedItems))
-		cachedFeeds[lo.FromPtr(location)] = feed
+		feed = newFeed
+		c.cachedFeeds[key] = feed
+	}
+	feed.markRequested()
+
+	var evictedKey string
+	if len(c.cachedFeeds) > c.maxCachedFeeds {
+		evictedKey = deleteOldestCachedFeed(c.cachedFeeds, key)
+	}
+	c.cachedFeedsMutex.Unlock()
+
+	// Delete the evicted feed without holding the lock, as deleting it can be slow
+	if evictedKey != "" {
+		c.metrics.feedCacheEvictions.Inc()
+		c.deleteStoredFeed(evictedKey)
+	}
+
+	return feed
+}
+
+// isCachedFeed returns whether a feed is the cached feed of a key
+func (c *Client) isCachedFeed(key string, feed *Feed) bool {
+	c.cachedFeedsMutex.Lock()
+	defer c.cachedFeedsMutex.Unlock()
+	return c.cachedFeeds[key] == feed
+}
+
+// refreshFeed refreshes a feed with the configured number of event pages and saves it.
+// If the feed is already being refreshed, the in progress refresh is waited for instead of starting
+// another. The refresh is only cancelled once the contexts of everything waiting for it are cancelled.
+func (c *Client) refreshFeed(ctx context.Context, key string, feed *Feed) error {
+	c.feedRefreshesMutex.Lock()
+	refresh, isRefreshing := c.feedRefreshes[key]
+	if !isRefreshing {
+		refreshCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		refresh = &feedRefresh{done: make(chan struct{}), cancel: cancel}
+		c.feedRefreshes[key] = refresh
+
+		go func() {
+			defer cancel()
+
+			err := feed.Update(refreshCtx, c.eventPages)
+			var partialErr *PartialError
+			if err == nil || errors.As(err, &partialErr) {
+				c.saveFeed(key, feed)
+			}
+
+			c.feedRefreshesMutex.Lock()
+			if c.feedRefreshes[key] == refresh {
+				delete(c.feedRefreshes, key)
+			}
+			refresh.err = err
+			close(refresh.done)
+			c.feedRefreshesMutex.Unlock()
+		}()
+	}
+	refresh.waiters++
+	c.feedRefreshesMutex.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.err
 
-		if len(cachedFeeds) > 10 {
-			deleteOldestCachedFeed(cachedFeeds)
+	case <-ctx.Done():
+		c.feedRefreshesMutex.Lock()
+		defer c.feedRefreshesMutex.Unlock()
+
+		// Cancel the refresh if nothing else is waiting for it. It is removed
+		// straight away so later refreshes do not wait for a cancelled refresh.
+		refresh.waiters--
+		if refresh.waiters == 0 {
+			refresh.cancel()
+			if c.feedRefreshes[key] == refresh {
+				delete(c.feedRefreshes, key)
+			}
 		}
+
+		return ctx.Err()
 	}
+}
 
-	// If there is a debounce and we are within the debounce period, return the cached feed
-	if debounceTime != nil && time.Since(feed.UpdatedAt()) < *debounceTime {
-		return feed, nil
+// staleFeed returns a feed that could not be refreshed along with a StaleFeedError.
+// If the feed has never been fetched, there is nothing to serve so only the error is returned.
+func staleFeed(feed *Feed, err error) (*Feed, error) {
+	refreshedAt := feed.RefreshedAt()
+	if refreshedAt.IsZero() {
+		return nil, err
+	}
+	return feed, &StaleFeedError{Err: err, RefreshedAt: refreshedAt}
+}
+
+// SaveCachedFeeds saves all cached feeds of the client to its feed store, if it has one.
+// Feeds are saved whenever they are updated, so this is only needed to
+// ensure all state is flushed e.g. before exiting.
+func (c *Client) SaveCachedFeeds() error {
+	if c.store == nil {
+		return nil
 	}
 
-	// Update feed with event pages
-	err := feed.Update(ctx, numEventPages)
+	c.cachedFeedsMutex.Lock()
+	feedsToSave := make(map[string]*Feed, len(c.cachedFeeds))
+	for key, feed := range c.cachedFeeds {
+		feedsToSave[key] = feed
+	}
+	c.cachedFeedsMutex.Unlock()
+
+	var errs error
+	for key, feed := range feedsToSave {
+		if feed.RefreshedAt().IsZero() {
+			continue
+		}
+
+		err := c.store.SaveFeed(key, feed.Snapshot())
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("failed to save feed %q: %w", key, err))
+		}
+	}
+
+	return errs
+}
+
+// loadFeed restores a feed from the feed store, if there is one and it contains the feed
+func (c *Client) loadFeed(key string, feed *Feed) {
+	if c.store == nil {
+		return
+	}
+
+	snapshot, err := c.store.LoadFeed(key)
 	if err != nil {
-		return nil, err
+		if !errors.Is(err, ErrFeedNotFound) {
+			c.logger.Warn("Failed to load feed from store", "feed", key, "error", err)
+		}
+		return
 	}
 
-	return feed, nil
+	feed.Restore(snapshot)
 }
 
-func eventToFeedItem(event Event) *feeds.Item {
+// saveFeed saves a cached feed to the feed store, if there is one. Feeds that have
+// been evicted from the cache while being refreshed are not saved, as they have been deleted.
+func (c *Client) saveFeed(key string, feed *Feed) {
+	if c.store == nil || !c.isCachedFeed(key, feed) {
+		return
+	}
+
+	err := c.store.SaveFeed(key, feed.Snapshot())
+	if err != nil {
+		c.logger.Warn("Failed to save feed to store", "feed", key, "error", err)
+	}
+}
+
+// eventToFeedItem converts an event to a feed item.
+// If the event detail is not nil, it is used as the feed item content.
+func (c *Client) eventToFeedItem(ctx context.Context, event Event, detail *EventDetail) *feeds.Item {
 	return &feeds.Item{
 		Id:          lo.Ternary(event.Id == 0, "", strconv.Itoa(event.Id)),
 		Title:       event.Title,
 		Link:        &feeds.Link{Href: event.Link},
 		Description: fmt.Sprintf("%s | %s | %s", event.Date, event.Location, event.Category),
 		Enclosure:   &feeds.Enclosure{Url: event.Image, Type: "image/jpeg", Length: "0"},
-		Created:     time.Now(),
+		Content:     c.eventContent(ctx, event, detail),
+		Created:     c.now(),
+	}
+}
+
+// eventContent renders an event detail as feed item content.
+// If the event detail is nil or cannot be rendered, the content is empty.
+func (c *Client) eventContent(ctx context.Context, event Event, detail *EventDetail) string {
+	if detail == nil {
+		return ""
+	}
+
+	content, err := detail.HTML()
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to render event detail", "eventId", event.Id, "error", err)
 	}
+
+	return content
 }
 
-func deleteOldestCachedFeed(cachedFeeds map[string]*Feed) {
-	oldestTime := time.Now()
+// deleteStoredFeed deletes a feed from the feed store, if there is one
+func (c *Client) deleteStoredFeed(key string) {
+	if c.store == nil {
+		return
+	}
+
+	err := c.store.DeleteFeed(key)
+	if err != nil {
+		c.logger.Warn("Failed to delete feed from store", "feed", key, "error", err)
+	}
+}
 
-	// Find the oldest cached feed
+// deleteOldestCachedFeed deletes the least recently requested cached feed, other than the feed with the
+// kept key, returning its key. The kept feed has just been requested, so should never be deleted.
+func deleteOldestCachedFeed(cachedFeeds map[string]*Feed, keepKey string) string {
 	var oldestKey string
+	var oldestTime time.Time
 	for key, cachedFeed := range cachedFeeds {
-		if cachedFeed.UpdatedAt().Before(oldestTime) {
+		if key == keepKey {
+			continue
+		}
+
+		requestedAt := cachedFeed.RequestedAt()
+		if oldestKey == "" || requestedAt.Before(oldestTime) {
 			oldestKey = key
-			oldestTime = cachedFeed.UpdatedAt()
+			oldestTime = requestedAt
 		}
 	}
 
+	if oldestKey == "" {
+		return ""
+	}
+
 	delete(cachedFeeds, oldestKey)
+
+	return oldestKey
 }
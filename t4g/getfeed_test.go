@@ -0,0 +1,323 @@
+package t4g_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+const newEventId = t4gtest.FirstEventId + 1
+
+var newEventPage = fmt.Sprintf(
+	`<div class="card event_card"><div class="card-body"><a href="/events/%d"><h5 class="card-title">New Event</h5></a>`+
+		`<div class="col">The O2, London</div><div class="col">Sat 2nd Mar 2024 7:00pm</div><div class="col">Music</div>`+
+		`</div></div>`,
+	newEventId,
+)
+
+func TestFeedUpdate(t *testing.T) {
+	server := t4gtest.NewServer(t)
+
+	// Start with only the first page of events
+	server.SetPageStatus(2, http.StatusNotFound)
+
+	feed := server.Client(t).NewFeed(t4g.FeedInput{Location: lo.ToPtr("update")})
+	err := feed.Update(context.Background(), 2)
+
+	var partialErr *t4g.PartialError
+	require.ErrorAs(t, err, &partialErr)
+	require.Equal(t, eventIds(feed.Events())[0], t4gtest.FirstEventId)
+	require.Len(t, feed.Events(), t4gtest.Page1Events)
+	require.False(t, feed.UpdatedAt().IsZero())
+
+	// Items should have content from the event pages
+	snapshot := feed.Snapshot()
+	require.Equal(t, strconv.Itoa(t4gtest.FirstEventId), snapshot.Items[0].Id)
+	require.Contains(t, snapshot.Items[0].Content, "Maximum 2 tickets per member")
+	require.Equal(
+		t, "Sat 20th Jan 2024 7:30pm | Victoria Palace Theatre, London | Theatre", snapshot.Items[0].Description,
+	)
+
+	// Updating with the same events should not change the feed
+	updatedAt := feed.UpdatedAt()
+	err = feed.Update(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, updatedAt, feed.UpdatedAt())
+	require.Equal(t, snapshot.Items, feed.Snapshot().Items)
+
+	// Events on the second page are older than the events in the feed, so should not be added
+	server.SetPageStatus(2, 0)
+	err = feed.Update(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, feed.Events(), t4gtest.Page1Events)
+
+	// New events should be added
+	server.SetPageContent(1, newEventPage)
+	err = feed.Update(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, newEventId, feed.Events()[0].Id)
+	require.Len(t, feed.Events(), t4gtest.Page1Events+1)
+	require.True(t, feed.UpdatedAt().After(updatedAt))
+}
+
+func TestFeedUpdateError(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	server.SetPageStatus(1, http.StatusInternalServerError)
+
+	feed := server.Client(t).NewFeed(t4g.FeedInput{Location: lo.ToPtr("update-error")})
+	err := feed.Update(context.Background(), 1)
+	require.Error(t, err)
+	require.Empty(t, feed.Events())
+	require.True(t, feed.RefreshedAt().IsZero())
+}
+
+func TestFeedUpdateRetriesFailedEventDetails(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	server.SetEventStatus(http.StatusInternalServerError)
+	clock := newFakeClock()
+
+	feed := server.Client(t, t4g.WithClock(clock.Now)).NewFeed(t4g.FeedInput{Location: lo.ToPtr("update-details")})
+	err := feed.Update(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, feed.Events(), t4gtest.Page1Events)
+	require.Empty(t, feed.Snapshot().Items[0].Content)
+
+	// Items without content should get it once their event details can be fetched
+	server.SetEventStatus(0)
+	clock.Advance(time.Minute)
+	err = feed.Update(context.Background(), 1)
+	require.NoError(t, err)
+	require.Contains(t, feed.Snapshot().Items[0].Content, "Maximum 2 tickets per member")
+	require.Equal(t, clock.Now(), feed.UpdatedAt())
+
+	// Event details should not be fetched again once every item has content
+	eventRequests := server.EventRequests()
+	err = feed.Update(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, eventRequests, server.EventRequests())
+}
+
+func TestFeedRender(t *testing.T) {
+	feed := t4gtest.NewServer(t).Client(t).NewFeed(t4g.FeedInput{Location: lo.ToPtr("render")})
+	err := feed.Update(context.Background(), 1)
+	require.NoError(t, err)
+
+	for _, format := range t4g.FeedFormats {
+		feedContent, err := feed.Render(format)
+		require.NoError(t, err, format)
+		require.Contains(t, feedContent, "Hamilton", format)
+	}
+
+	_, err = feed.Render("pdf")
+	require.ErrorContains(t, err, `unknown feed format "pdf"`)
+}
+
+func TestFetchFeed(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	client := server.Client(t, t4g.WithEventPages(2))
+
+	debounceTime := time.Hour
+	input := t4g.FeedInput{Location: lo.ToPtr("fetch")}
+
+	feed, err := client.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+	require.Len(t, feed.Events(), t4gtest.Page1Events+t4gtest.Page2Events)
+	require.Equal(t, 1, server.PageRequests("fetch", 1))
+	require.Equal(t, 1, server.PageRequests("fetch", 2))
+
+	// Fetching within the debounce time should return the cached feed
+	cachedFeed, err := client.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+	require.Same(t, feed, cachedFeed)
+	require.Equal(t, 1, server.PageRequests("fetch", 1))
+
+	// Fetching without a debounce time should refresh the feed
+	refreshedFeed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+	require.Same(t, feed, refreshedFeed)
+	require.Equal(t, 2, server.PageRequests("fetch", 1))
+}
+
+func TestFetchFeedStale(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	client := server.Client(t)
+
+	input := t4g.FeedInput{Location: lo.ToPtr("fetch-stale")}
+	feed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+
+	// Failed refreshes should return the stale feed, and not be retried straight away
+	server.SetPageStatus(1, http.StatusServiceUnavailable)
+	for attempt := 0; attempt < 2; attempt++ {
+		staleFeed, err := client.FetchFeed(context.Background(), input, nil)
+
+		var staleErr *t4g.StaleFeedError
+		require.ErrorAs(t, err, &staleErr)
+		require.Same(t, feed, staleFeed)
+		require.Equal(t, feed.RefreshedAt(), staleErr.RefreshedAt)
+	}
+	require.Equal(t, 2, server.PageRequests("fetch-stale", 1))
+
+	// Feeds that have never been fetched have nothing to serve
+	_, err = client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr("fetch-unavailable")}, nil)
+	require.Error(t, err)
+
+	var staleErr *t4g.StaleFeedError
+	require.False(t, errors.As(err, &staleErr))
+}
+
+func TestFetchFeedSharesRefreshes(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	client := server.Client(t)
+	unblock := server.BlockLocation("slow")
+	defer unblock()
+
+	// Fetch the slow feed many times concurrently
+	debounceTime := time.Hour
+	var wg sync.WaitGroup
+	feeds := make([]*t4g.Feed, 5)
+	errs := make([]error, len(feeds))
+	for idx := range feeds {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			input := t4g.FeedInput{Location: lo.ToPtr("slow")}
+			feeds[idx], errs[idx] = client.FetchFeed(context.Background(), input, &debounceTime)
+		}(idx)
+	}
+
+	// Fetches of other feeds are not blocked by the slow feed
+	_, err := client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr("fast")}, nil)
+	require.NoError(t, err)
+
+	unblock()
+	wg.Wait()
+
+	require.Equal(t, 1, server.PageRequests("slow", 1))
+	for idx, feed := range feeds {
+		require.NoError(t, errs[idx])
+		require.Same(t, feeds[0], feed)
+	}
+}
+
+func TestFetchFeedKeepsNewFeedWhenCacheIsFull(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	client := server.Client(t, t4g.WithMaxCachedFeeds(1))
+
+	debounceTime := time.Hour
+	for _, location := range []string{"full-first", "full-second", "full-second"} {
+		_, err := client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr(location)}, &debounceTime)
+		require.NoError(t, err)
+	}
+
+	// The second feed should have been cached rather than evicted straight away
+	require.Equal(t, 1, server.PageRequests("full-second", 1))
+}
+
+func TestFetchFeedCopiesInput(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	client := server.Client(t)
+
+	location := "copy"
+	categories := []string{"Theatre"}
+	input := t4g.FeedInput{Location: &location, Filter: t4g.EventFilter{Categories: categories}}
+	feed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+
+	// Changing the input after fetching should not change the feed
+	location = "changed"
+	categories[0] = "Music"
+
+	sameInput := t4g.FeedInput{Location: lo.ToPtr("copy"), Filter: t4g.EventFilter{Categories: []string{"Theatre"}}}
+	refreshedFeed, err := client.FetchFeed(context.Background(), sameInput, nil)
+	require.NoError(t, err)
+	require.Same(t, feed, refreshedFeed)
+	require.Equal(t, 2, server.PageRequests("copy", 1))
+	require.Zero(t, server.PageRequests("changed", 1))
+	for _, event := range feed.Events() {
+		require.Equal(t, "Theatre", event.Category)
+	}
+}
+
+func TestFetchFeedPortal(t *testing.T) {
+	client := t4gtest.NewServer(t).Client(t)
+
+	mirror, err := client.LookupPortal(t4gtest.MirrorPortal)
+	require.NoError(t, err)
+
+	nhsFeed, err := client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr("portal")}, nil)
+	require.NoError(t, err)
+	mirrorFeed, err := client.FetchFeed(
+		context.Background(), t4g.FeedInput{Portal: &mirror, Location: lo.ToPtr("portal")}, nil,
+	)
+	require.NoError(t, err)
+
+	// Feeds of different portals are independent, and link to their portal
+	require.NotSame(t, nhsFeed, mirrorFeed)
+	nhsRss, err := nhsFeed.ToRss()
+	require.NoError(t, err)
+	require.Contains(t, nhsRss, "<title>T4G Feed: Portal</title>")
+
+	mirrorRss, err := mirrorFeed.ToRss()
+	require.NoError(t, err)
+	require.Contains(t, mirrorRss, "<title>T4G Feed: Portal [mirror]</title>")
+	require.Contains(t, mirrorRss, "<link>"+mirror.URL.String()+"/events?")
+
+	nhsEvent := nhsFeed.Events()[0]
+	mirrorEvent := mirrorFeed.Events()[0]
+	require.Equal(t, nhsEvent.Id, mirrorEvent.Id)
+	require.Equal(t, mirror.URL.String()+"/events/"+strconv.Itoa(nhsEvent.Id), mirrorEvent.Link)
+	require.NotEqual(t, nhsEvent.Link, mirrorEvent.Link)
+}
+
+func TestFetchFeedNotBlockedByListeners(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	client := server.Client(t, t4g.WithMaxCachedFeeds(1))
+
+	listenerCalled := make(chan struct{})
+	releaseListener := make(chan struct{})
+	client.AddNewEventsListener(func(context.Context, t4g.FeedInput, []t4g.Event) {
+		close(listenerCalled)
+		<-releaseListener
+	})
+
+	input := t4g.FeedInput{Location: lo.ToPtr("listener-blocked")}
+	feed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+
+	// Refresh the feed with a new event, blocking in the listener
+	server.SetPageContent(1, newEventPage)
+	refreshed := make(chan error)
+	go func() {
+		_, err := client.FetchFeed(context.Background(), input, nil)
+		refreshed <- err
+	}()
+	<-listenerCalled
+
+	// The blocked feed can still be read, and other feeds fetched (evicting the blocked feed)
+	fetched := make(chan error)
+	go func() {
+		_, err := client.FetchFeed(context.Background(), t4g.FeedInput{Location: lo.ToPtr("listener-other")}, nil)
+		fetched <- err
+	}()
+	select {
+	case err := <-fetched:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("fetching another feed was blocked by a listener")
+	}
+	require.Equal(t, newEventId, feed.Events()[0].Id)
+
+	close(releaseListener)
+	require.NoError(t, <-refreshed)
+}
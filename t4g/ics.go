@@ -0,0 +1,134 @@
+package t4g
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/feeds"
+)
+
+const (
+	icsDateFormat     = "20060102"
+	icsDateTimeFormat = "20060102T150405Z"
+	icsMaxLineLength  = 75 // Maximum line length in octets, excluding the line break
+)
+
+var icsTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// ToICS renders the feed as an iCalendar (RFC 5545) calendar, with an event for each
+// feed item whose dates are known. Event UIDs are derived from event ids, so are stable
+// between refreshes, allowing calendar clients to update rather than duplicate events.
+func (f *Feed) ToICS() (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var calendar icsWriter
+	calendar.writeLine("BEGIN:VCALENDAR")
+	calendar.writeLine("VERSION:2.0")
+	calendar.writeLine("PRODID:-//t4g-feed//Tickets For Good Feed//EN")
+	calendar.writeLine("CALSCALE:GREGORIAN")
+	calendar.writeLine("METHOD:PUBLISH")
+	calendar.writeProperty("X-WR-CALNAME", escapeICSText(f.feed.Title))
+	calendar.writeProperty("X-WR-CALDESC", escapeICSText(f.feed.Description))
+
+	for _, item := range f.feed.Items {
+		event, ok := f.events[item.Id]
+		if !ok || event.StartsAt.IsZero() {
+			continue
+		}
+
+		writeICSEvent(&calendar, item, event, f.input.Portal.URL.Host)
+	}
+
+	calendar.writeLine("END:VCALENDAR")
+
+	return calendar.String(), nil
+}
+
+func writeICSEvent(calendar *icsWriter, item *feeds.Item, event Event, portalHost string) {
+	calendar.writeLine("BEGIN:VEVENT")
+	calendar.writeProperty("UID", eventUID(event, portalHost))
+
+	// Use the time the item was added to the feed, so the event is not seen as changed on every refresh
+	calendar.writeProperty("DTSTAMP", item.Created.UTC().Format(icsDateTimeFormat))
+
+	if event.AllDay {
+		// All day end dates are exclusive, so the end is the day after the last day
+		endsAt := event.EndsAt
+		if endsAt.IsZero() {
+			endsAt = event.StartsAt
+		}
+
+		calendar.writeProperty("DTSTART;VALUE=DATE", event.StartsAt.Format(icsDateFormat))
+		calendar.writeProperty("DTEND;VALUE=DATE", endsAt.AddDate(0, 0, 1).Format(icsDateFormat))
+	} else {
+		calendar.writeProperty("DTSTART", event.StartsAt.UTC().Format(icsDateTimeFormat))
+		if !event.EndsAt.IsZero() {
+			calendar.writeProperty("DTEND", event.EndsAt.UTC().Format(icsDateTimeFormat))
+		}
+	}
+
+	calendar.writeProperty("SUMMARY", escapeICSText(event.Title))
+	if event.Location != "" {
+		calendar.writeProperty("LOCATION", escapeICSText(event.Location))
+	}
+	if event.Link != "" {
+		calendar.writeProperty("URL", event.Link)
+	}
+	if event.Category != "" {
+		calendar.writeProperty("CATEGORIES", escapeICSText(event.Category))
+	}
+	calendar.writeProperty("DESCRIPTION", escapeICSText(item.Description))
+
+	calendar.writeLine("END:VEVENT")
+}
+
+// eventUID returns a globally unique id of an event that is stable between refreshes.
+// The domain is the host of the event link, falling back to the host of the portal of the feed.
+func eventUID(event Event, portalHost string) string {
+	host := portalHost
+	if link, err := url.Parse(event.Link); err == nil && link.Host != "" {
+		host = link.Host
+	}
+
+	return fmt.Sprintf("event-%d@%s", event.Id, host)
+}
+
+func escapeICSText(text string) string {
+	return icsTextEscaper.Replace(text)
+}
+
+// icsWriter writes iCalendar content lines, folding lines longer than the maximum line length
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) writeProperty(name, value string) {
+	w.writeLine(name + ":" + value)
+}
+
+func (w *icsWriter) writeLine(line string) {
+	lineLength := 0
+	for _, char := range line {
+		// Fold lines by inserting a line break followed by a space.
+		// The space counts towards the length of the next line.
+		charLength := utf8.RuneLen(char)
+		if lineLength+charLength > icsMaxLineLength {
+			w.WriteString("\r\n ")
+			lineLength = 1
+		}
+
+		w.WriteRune(char)
+		lineLength += charLength
+	}
+
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,83 @@
+package t4g_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/gorilla/feeds"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedToICS(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := &t4g.FeedSnapshot{
+		Items: []*feeds.Item{
+			{Id: "3", Title: "Hamlet", Description: "Sat 20 Jan 2024 7:30pm | The Globe, London | Theatre", Created: created},
+			{Id: "2", Title: "Festival", Created: created},
+			{Id: "1", Title: "Multiple Dates", Created: created},
+		},
+		Events: []t4g.Event{
+			{
+				Id:       3,
+				Title:    "Hamlet",
+				Link:     "https://nhs.ticketsforgood.co.uk/events/3",
+				Location: "The Globe, London",
+				Category: "Theatre",
+				EventDates: t4g.EventDates{
+					StartsAt: time.Date(2024, 1, 20, 19, 30, 0, 0, london),
+				},
+			},
+			{
+				Id:    2,
+				Title: "Festival",
+				EventDates: t4g.EventDates{
+					StartsAt: time.Date(2024, 7, 19, 0, 0, 0, 0, london),
+					EndsAt:   time.Date(2024, 7, 21, 0, 0, 0, 0, london),
+					AllDay:   true,
+				},
+			},
+			{
+				Id:         1,
+				Title:      "Multiple Dates",
+				EventDates: t4g.EventDates{MultipleDates: true},
+			},
+		},
+	}
+
+	client, err := t4g.NewClient()
+	require.NoError(t, err)
+
+	feed := client.NewFeed(t4g.FeedInput{Location: lo.ToPtr("london")})
+	feed.Restore(snapshot)
+
+	calendar, err := feed.ToICS()
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\n"))
+	require.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+
+	// Events without dates are skipped
+	require.Equal(t, 2, strings.Count(calendar, "BEGIN:VEVENT"))
+	require.NotContains(t, calendar, "event-1@")
+
+	require.Contains(t, calendar, "UID:event-3@nhs.ticketsforgood.co.uk\r\n")
+	require.Contains(t, calendar, "DTSTAMP:20240101T120000Z\r\n")
+	require.Contains(t, calendar, "DTSTART:20240120T193000Z\r\n")
+	require.Contains(t, calendar, "LOCATION:The Globe\\, London\r\n")
+	require.Contains(t, calendar, "URL:https://nhs.ticketsforgood.co.uk/events/3\r\n")
+
+	require.Contains(t, calendar, "UID:event-2@nhs.ticketsforgood.co.uk\r\n")
+	require.Contains(t, calendar, "DTSTART;VALUE=DATE:20240719\r\n")
+	require.Contains(t, calendar, "DTEND;VALUE=DATE:20240722\r\n")
+
+	// Lines are folded to 75 octets
+	for _, line := range strings.Split(calendar, "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+}
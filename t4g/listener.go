@@ -0,0 +1,24 @@
+package t4g
+
+import "context"
+
+// NewEventsListener is called with the events that are newly added to a feed when it is updated.
+// Listeners are called synchronously at the end of the update, so should not block, but the feed
+// can still be read (and other feeds fetched) while they run.
+type NewEventsListener func(ctx context.Context, input FeedInput, events []Event)
+
+// AddNewEventsListener adds a listener that is called when new events are added to any feed of the client
+func (c *Client) AddNewEventsListener(listener NewEventsListener) {
+	c.newEventsListenersMutex.Lock()
+	defer c.newEventsListenersMutex.Unlock()
+	c.newEventsListeners = append(c.newEventsListeners, listener)
+}
+
+func (c *Client) notifyNewEventsListeners(ctx context.Context, input FeedInput, events []Event) {
+	c.newEventsListenersMutex.RLock()
+	defer c.newEventsListenersMutex.RUnlock()
+
+	for _, listener := range c.newEventsListeners {
+		listener(ctx, input, events)
+	}
+}
@@ -0,0 +1,165 @@
+package t4g
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "t4g"
+
+// metrics are the prometheus metrics of a client
+type metrics struct {
+	upstreamRequests        *prometheus.CounterVec
+	upstreamRequestDuration *prometheus.HistogramVec
+	upstreamResponseBytes   prometheus.Histogram
+	failedEventPages        prometheus.Counter
+	parseFailures           *prometheus.CounterVec
+	pageEventCount          prometheus.Histogram
+	feedUpdateNewItems      prometheus.Histogram
+	feedCacheHits           prometheus.Counter
+	feedCacheMisses         prometheus.Counter
+	feedCacheEvictions      prometheus.Counter
+}
+
+// newMetrics creates the metrics of a client, registering them with a registerer if it is not nil.
+// Metrics already registered by another client are shared with it rather than registered again.
+func newMetrics(registerer prometheus.Registerer) (*metrics, error) {
+	var errs []error
+	m := &metrics{
+		upstreamRequests: registerCollector(registerer, &errs, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "upstream_requests_total",
+				Help:      "Number of pages fetched from Tickets For Good, by response status code or error if there was none",
+			},
+			[]string{"status"},
+		)),
+		upstreamRequestDuration: registerCollector(registerer, &errs, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Name:      "upstream_request_duration_seconds",
+				Help:      "Time taken to fetch pages from Tickets For Good, by response status code",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"status"},
+		)),
+		upstreamResponseBytes: registerCollector(registerer, &errs, prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Name:      "upstream_response_bytes",
+				Help:      "Size of pages fetched from Tickets For Good",
+				Buckets:   prometheus.ExponentialBuckets(1024, 2, 10), // 1KiB to 512KiB
+			},
+		)),
+		failedEventPages: registerCollector(registerer, &errs, prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "failed_event_pages_total",
+				Help:      "Number of event pages that could not be fetched when other pages could, giving partial results",
+			},
+		)),
+		parseFailures: registerCollector(registerer, &errs, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "parse_failures_total",
+				Help:      "Number of failures parsing Tickets For Good pages, by what was being parsed",
+			},
+			[]string{"type"},
+		)),
+		pageEventCount: registerCollector(registerer, &errs, prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Name:      "page_events",
+				Help:      "Number of events on each events page fetched from Tickets For Good",
+				Buckets:   prometheus.LinearBuckets(0, 2, 8), // 0 to 14
+			},
+		)),
+		feedUpdateNewItems: registerCollector(registerer, &errs, prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: metricsNamespace,
+				Name:      "feed_update_new_items",
+				Help:      "Number of new items added to a feed by each feed update",
+				Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+			},
+		)),
+		feedCacheHits: registerCollector(registerer, &errs, prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "feed_cache_hits_total",
+				Help:      "Number of feed fetches served from the cache without waiting for the feed to be refreshed",
+			},
+		)),
+		feedCacheMisses: registerCollector(registerer, &errs, prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "feed_cache_misses_total",
+				Help:      "Number of feed fetches that had to wait for the feed to be refreshed",
+			},
+		)),
+		feedCacheEvictions: registerCollector(registerer, &errs, prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "feed_cache_evictions_total",
+				Help:      "Number of feeds removed from the cache to keep it within its maximum size",
+			},
+		)),
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// registerCollector registers a collector with a registerer if it is not nil, returning the collector to use.
+// If an equal collector is already registered, that collector is returned instead.
+// Other registration errors are added to errs.
+func registerCollector[T prometheus.Collector](registerer prometheus.Registerer, errs *[]error, collector T) T {
+	if registerer == nil {
+		return collector
+	}
+
+	err := registerer.Register(collector)
+	var alreadyRegisteredErr prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegisteredErr) {
+		if existing, ok := alreadyRegisteredErr.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	if err != nil {
+		*errs = append(*errs, err)
+	}
+
+	return collector
+}
+
+// Types of parse failure
+const (
+	parseFailureEventsPage = "events_page"
+	parseFailureEventCard  = "event_card"
+	parseFailureEventPage  = "event_page"
+	parseFailureEventDate  = "event_date"
+)
+
+// observeUpstreamRequest records a page fetch from Tickets For Good that started at a time.
+// Metrics may be nil, as upstream clients can be used without a client.
+func (m *metrics) observeUpstreamRequest(status string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.upstreamRequests.WithLabelValues(status).Inc()
+	m.upstreamRequestDuration.WithLabelValues(status).Observe(time.Since(start).Seconds())
+}
+
+// observeUpstreamResponse records the size of a page fetched from Tickets For Good.
+// Metrics may be nil, as upstream clients can be used without a client.
+func (m *metrics) observeUpstreamResponse(bodyBytes int) {
+	if m == nil {
+		return
+	}
+	m.upstreamResponseBytes.Observe(float64(bodyBytes))
+}
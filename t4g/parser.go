@@ -12,14 +12,21 @@ import (
 // numbersRegex is a regex to match a the first consecutive numbers in a link.
 var numbersRegex = regexp.MustCompile(`\d+`)
 
-// PagserExtractAttrNumbers is a pagser function that extract the first consecutive numbers form an attribute
+// PagserExtractAttrNumbers is a pagser function that extract the first consecutive numbers form an attribute.
+// If the attribute has no numbers, 0 is returned so one malformed element does not fail the whole page.
 // Based on: https://github.com/foolin/pagser/blob/v0.1.6/builtin_functions.go#L43
 func PagserAttrNumbers(node *goquery.Selection, args ...string) (any, error) {
 	if len(args) != 1 {
 		return "", fmt.Errorf("attrNumbers(name) must have one argument")
 	}
 	attrValue, _ := node.Attr(args[0])
-	return strconv.Atoi(numbersRegex.FindString(attrValue))
+
+	numbers := numbersRegex.FindString(attrValue)
+	if numbers == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(numbers)
 }
 
 func NewHTMLParser() *pagser.Pagser {
@@ -0,0 +1,108 @@
+package t4g
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ahobsonsayers/t4g-feed/utils"
+	"github.com/samber/lo"
+)
+
+// DefaultPortalName is the name of the Tickets For Good NHS portal, which is used by default
+const DefaultPortalName = "nhs"
+
+// ErrUnknownPortal is returned when looking up a portal that a client does not allow
+var ErrUnknownPortal = errors.New("unknown portal")
+
+// portalNameRegex matches valid portal names
+var portalNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Portal is a Tickets For Good site events can be fetched from. Tickets For Good runs
+// a portal for each group of people eligible for tickets e.g. NHS staff.
+type Portal struct {
+	Name string   // Name used to choose the portal e.g. nhs
+	URL  *url.URL // Base url of the portal
+}
+
+// NewPortal creates a portal, validating its name and base url
+func NewPortal(name, baseUrl string) (Portal, error) {
+	if !portalNameRegex.MatchString(name) {
+		return Portal{}, fmt.Errorf("invalid portal name %q: must be lowercase letters, numbers and dashes", name)
+	}
+
+	parsedUrl, err := url.Parse(baseUrl)
+	if err != nil {
+		return Portal{}, fmt.Errorf("invalid url of portal %q: %w", name, err)
+	}
+	if (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return Portal{}, fmt.Errorf("invalid url %q of portal %q: must be an absolute http or https url", baseUrl, name)
+	}
+
+	// Events are fetched from paths relative to the base url
+	parsedUrl.Path = strings.TrimSuffix(parsedUrl.Path, "/")
+	parsedUrl.RawQuery = ""
+	parsedUrl.Fragment = ""
+
+	return Portal{Name: name, URL: parsedUrl}, nil
+}
+
+// DefaultPortals returns the base urls of the portals that are allowed by default
+func DefaultPortals() map[string]string {
+	return map[string]string{DefaultPortalName: TicketsForGoodURL}
+}
+
+// LookupPortal gets a portal allowed by the client by name.
+// If the portal is not allowed, ErrUnknownPortal is returned.
+func (c *Client) LookupPortal(name string) (Portal, error) {
+	portal, ok := c.portals[strings.ToLower(name)]
+	if !ok {
+		return Portal{}, fmt.Errorf(
+			"%w %q: must be one of %s", ErrUnknownPortal, name, strings.Join(c.PortalNames(), ", "),
+		)
+	}
+
+	return portal.clone(), nil
+}
+
+// DefaultPortal returns the portal used when a portal is not chosen
+func (c *Client) DefaultPortal() Portal {
+	return c.portals[c.defaultPortal].clone()
+}
+
+// PortalNames returns the sorted names of the portals allowed by the client
+func (c *Client) PortalNames() []string {
+	names := lo.Keys(c.portals)
+	sort.Strings(names)
+	return names
+}
+
+// portalOrDefault returns the portal if it is set, otherwise the default portal
+func (c *Client) portalOrDefault(portal *Portal) Portal {
+	if portal == nil {
+		return c.DefaultPortal()
+	}
+	return portal.clone()
+}
+
+func (p Portal) clone() Portal {
+	return Portal{Name: p.Name, URL: utils.CloneURL(p.URL)}
+}
+
+func newPortals(portalUrls map[string]string) (map[string]Portal, error) {
+	newPortals := make(map[string]Portal, len(portalUrls))
+	var errs error
+	for name, baseUrl := range portalUrls {
+		portal, err := NewPortal(name, baseUrl)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		newPortals[name] = portal
+	}
+
+	return newPortals, errs
+}
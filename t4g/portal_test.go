@@ -0,0 +1,70 @@
+package t4g_test
+
+import (
+	"testing"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPortal(t *testing.T) {
+	portal, err := t4g.NewPortal("mirror", "http://localhost:8080/t4g/?page=1")
+	require.NoError(t, err)
+	require.Equal(t, "mirror", portal.Name)
+	require.Equal(t, "http://localhost:8080/t4g", portal.URL.String())
+
+	_, err = t4g.NewPortal("Mirror Portal", "http://localhost:8080")
+	require.ErrorContains(t, err, "invalid portal name")
+
+	_, err = t4g.NewPortal("mirror", "localhost:8080")
+	require.ErrorContains(t, err, "must be an absolute http or https url")
+}
+
+func TestClientPortals(t *testing.T) {
+	_, err := t4g.NewClient(t4g.WithPortals(map[string]string{"mirror": "http://localhost:8080"}, t4g.DefaultPortalName))
+	require.ErrorContains(t, err, "default portal")
+
+	client, err := t4g.NewClient(t4g.WithPortals(
+		map[string]string{t4g.DefaultPortalName: t4g.TicketsForGoodURL, "mirror": "http://localhost:8080"},
+		"mirror",
+	))
+	require.NoError(t, err)
+	require.Equal(t, []string{"mirror", t4g.DefaultPortalName}, client.PortalNames())
+	require.Equal(t, "mirror", client.DefaultPortal().Name)
+
+	portal, err := client.LookupPortal("NHS")
+	require.NoError(t, err)
+	require.Equal(t, t4g.TicketsForGoodURL, portal.URL.String())
+
+	_, err = client.LookupPortal("other")
+	require.ErrorIs(t, err, t4g.ErrUnknownPortal)
+}
+
+func TestFeedInputKeyPortal(t *testing.T) {
+	client, err := t4g.NewClient(t4g.WithPortals(
+		map[string]string{t4g.DefaultPortalName: t4g.TicketsForGoodURL, "mirror": "http://localhost:8080"},
+		t4g.DefaultPortalName,
+	))
+	require.NoError(t, err)
+
+	nhs, err := client.LookupPortal(t4g.DefaultPortalName)
+	require.NoError(t, err)
+	mirror, err := client.LookupPortal("mirror")
+	require.NoError(t, err)
+
+	// Feeds of the default portal have the same key whether or not the portal is chosen
+	input := t4g.FeedInput{Location: lo.ToPtr("London")}
+	require.Equal(t, "london|30||||", input.Key())
+	require.Equal(t, input.Key(), t4g.FeedInput{Portal: &nhs, Location: lo.ToPtr("London")}.Key())
+	require.Equal(t, "london|30|||||mirror", t4g.FeedInput{Portal: &mirror, Location: lo.ToPtr("London")}.Key())
+
+	// Feeds without a portal use the default portal of their client
+	mirrorClient, err := t4g.NewClient(t4g.WithPortals(
+		map[string]string{t4g.DefaultPortalName: t4g.TicketsForGoodURL, "mirror": "http://localhost:8080"},
+		"mirror",
+	))
+	require.NoError(t, err)
+	require.Equal(t, input.Key(), client.FeedKey(input))
+	require.Equal(t, "london|30|||||mirror", mirrorClient.FeedKey(input))
+}
@@ -0,0 +1,145 @@
+package t4g
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type backgroundRefresher struct {
+	client      *Client
+	ctx         context.Context
+	interval    time.Duration
+	idleTimeout time.Duration
+
+	refreshing      map[string]bool // Feed key -> whether feed is currently being refreshed
+	stopped         bool            // Whether the refresh loop has stopped, so no more refreshes can be started
+	refreshingMutex sync.Mutex
+
+	// running tracks the refresh loop and all in progress refreshes
+	running sync.WaitGroup
+}
+
+// StartBackgroundRefresh starts refreshing cached feeds of the client in the background every interval,
+// until the context is cancelled. Feeds that have not been requested for the idle timeout
+// are not refreshed until they are requested again.
+//
+// While background refresh is running, FetchFeed returns cached feeds immediately
+// rather than waiting for them to be refreshed, unless they have never been fetched.
+//
+// The returned function waits for background refresh to stop after the context is cancelled,
+// including any in progress refreshes, which are cancelled with the context.
+func (c *Client) StartBackgroundRefresh(ctx context.Context, interval, idleTimeout time.Duration) (wait func()) {
+	refresher := &backgroundRefresher{
+		client:      c,
+		ctx:         ctx,
+		interval:    interval,
+		idleTimeout: idleTimeout,
+		refreshing:  map[string]bool{},
+	}
+
+	c.backgroundRefreshMutex.Lock()
+	c.backgroundRefresh = refresher
+	c.backgroundRefreshMutex.Unlock()
+
+	refresher.running.Add(1)
+	go refresher.run()
+
+	return refresher.running.Wait
+}
+
+func (c *Client) getBackgroundRefresher() *backgroundRefresher {
+	c.backgroundRefreshMutex.RLock()
+	defer c.backgroundRefreshMutex.RUnlock()
+	return c.backgroundRefresh
+}
+
+func (r *backgroundRefresher) run() {
+	defer r.running.Done()
+	defer func() {
+		// Stop refreshes being started once the loop is done, as waiting may then return
+		r.refreshingMutex.Lock()
+		r.stopped = true
+		r.refreshingMutex.Unlock()
+
+		r.client.backgroundRefreshMutex.Lock()
+		if r.client.backgroundRefresh == r {
+			r.client.backgroundRefresh = nil
+		}
+		r.client.backgroundRefreshMutex.Unlock()
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.refreshActiveFeeds()
+		}
+	}
+}
+
+// refreshActiveFeeds refreshes all cached feeds that have been requested within the idle timeout.
+// Refreshes are spread over a jitter period so the site is not hit with all refreshes at once.
+func (r *backgroundRefresher) refreshActiveFeeds() {
+	r.client.cachedFeedsMutex.Lock()
+	activeFeeds := make(map[string]*Feed, len(r.client.cachedFeeds))
+	for key, feed := range r.client.cachedFeeds {
+		if r.client.since(feed.RequestedAt()) < r.idleTimeout {
+			activeFeeds[key] = feed
+		}
+	}
+	r.client.cachedFeedsMutex.Unlock()
+
+	maxJitter := int64(r.interval / 2)
+	for key, feed := range activeFeeds {
+		jitter := time.Duration(rand.Int63n(maxJitter + 1))
+		r.refreshAfter(key, feed, jitter)
+	}
+}
+
+// refreshAfter refreshes a feed in the background after a delay.
+// If the feed is already being refreshed, or background refresh has stopped, nothing is done.
+func (r *backgroundRefresher) refreshAfter(key string, feed *Feed, delay time.Duration) {
+	r.refreshingMutex.Lock()
+	defer r.refreshingMutex.Unlock()
+
+	// Refreshes are added to running while holding the lock, so they cannot be
+	// added once the refresh loop has stopped and waiting may have returned
+	if r.stopped || r.ctx.Err() != nil || r.refreshing[key] {
+		return
+	}
+	r.refreshing[key] = true
+
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+		defer func() {
+			r.refreshingMutex.Lock()
+			delete(r.refreshing, key)
+			r.refreshingMutex.Unlock()
+		}()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		err := r.client.refreshFeed(r.ctx, key, feed)
+		var partialErr *PartialError
+		if errors.As(err, &partialErr) {
+			r.client.logger.Warn("Some event pages failed when refreshing feed in background", "feed", key, "error", err)
+		} else if err != nil && r.ctx.Err() == nil {
+			r.client.logger.Warn("Failed to refresh feed in background", "feed", key, "error", err)
+		}
+	}()
+}
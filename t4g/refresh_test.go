@@ -0,0 +1,177 @@
+package t4g_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock that only moves when advanced
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestBackgroundRefreshStopsRefreshingIdleFeeds(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	clock := newFakeClock()
+	client := server.Client(t, t4g.WithClock(clock.Now))
+
+	input := t4g.FeedInput{Location: lo.ToPtr("refresh-idle")}
+	_, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := client.StartBackgroundRefresh(ctx, 10*time.Millisecond, time.Hour)
+	defer wait()
+	defer cancel()
+
+	// Feeds requested within the idle timeout are refreshed every interval
+	require.Eventually(
+		t, func() bool { return server.PageRequests("refresh-idle", 1) >= 3 }, time.Second, time.Millisecond,
+	)
+
+	// Once the feed has not been requested for the idle timeout, it should no longer be refreshed
+	clock.Advance(2 * time.Hour)
+	time.Sleep(50 * time.Millisecond)
+	idleRequests := server.PageRequests("refresh-idle", 1)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, idleRequests, server.PageRequests("refresh-idle", 1))
+
+	// Requesting the feed again should resume refreshing it
+	debounceTime := time.Hour
+	_, err = client.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+	require.Eventually(
+		t, func() bool { return server.PageRequests("refresh-idle", 1) >= idleRequests+3 }, time.Second, time.Millisecond,
+	)
+}
+
+func TestBackgroundRefreshServesStaleFeedImmediately(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	clock := newFakeClock()
+	client := server.Client(t, t4g.WithClock(clock.Now))
+
+	input := t4g.FeedInput{Location: lo.ToPtr("refresh-stale")}
+	feed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+	refreshedAt := feed.RefreshedAt()
+
+	// The interval is long so only requests cause refreshes
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := client.StartBackgroundRefresh(ctx, time.Hour, time.Hour)
+	defer wait()
+	defer cancel()
+
+	unblock := server.BlockLocation("refresh-stale")
+	defer unblock()
+
+	// Once the debounce time has passed, the cached feed should be returned without waiting
+	// for the refresh, which carries on in the background
+	clock.Advance(2 * time.Hour)
+	debounceTime := time.Hour
+	staleFeed, err := client.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+	require.Same(t, feed, staleFeed)
+	require.Equal(t, refreshedAt, staleFeed.RefreshedAt())
+
+	unblock()
+	require.Eventually(t, func() bool { return feed.RefreshedAt().After(refreshedAt) }, time.Second, time.Millisecond)
+	require.Equal(t, 2, server.PageRequests("refresh-stale", 1))
+}
+
+func TestBackgroundRefreshStopsWhenCancelled(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	clock := newFakeClock()
+	client := server.Client(t, t4g.WithClock(clock.Now))
+
+	input := t4g.FeedInput{Location: lo.ToPtr("refresh-cancel")}
+	_, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+
+	// Block refreshes so one is in progress when the refresher is cancelled
+	unblock := server.BlockLocation("refresh-cancel")
+	defer unblock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := client.StartBackgroundRefresh(ctx, time.Hour, time.Hour)
+
+	clock.Advance(2 * time.Hour)
+	debounceTime := time.Hour
+	_, err = client.FetchFeed(context.Background(), input, &debounceTime)
+	require.NoError(t, err)
+
+	// Waiting should return once the refresher has stopped, cancelling the in progress refresh
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("background refresh did not stop after its context was cancelled")
+	}
+
+	// Once stopped, fetches should wait for feeds to be refreshed rather than refreshing in the background
+	unblock()
+	clock.Advance(time.Hour)
+	feed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+	require.Equal(t, clock.Now(), feed.RefreshedAt())
+}
+
+func TestBackgroundRefreshWaitsForRefreshesStartedWhileStopping(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	clock := newFakeClock()
+	client := server.Client(t, t4g.WithClock(clock.Now))
+
+	input := t4g.FeedInput{Location: lo.ToPtr("refresh-stopping")}
+	_, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+
+	// Fetch stale feeds while the refresher stops, so refreshes are started as it stops.
+	// Waiting should not return while refreshes can still be started.
+	for attempt := 0; attempt < 20; attempt++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := client.StartBackgroundRefresh(ctx, time.Hour, time.Hour)
+
+		fetchesDone := make(chan struct{})
+		go func() {
+			defer close(fetchesDone)
+			debounceTime := time.Minute
+			for ctx.Err() == nil {
+				clock.Advance(time.Hour)
+				_, _ = client.FetchFeed(context.Background(), input, &debounceTime)
+			}
+		}()
+
+		time.Sleep(time.Millisecond)
+		cancel()
+		wait()
+		<-fetchesDone
+	}
+}
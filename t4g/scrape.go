@@ -0,0 +1,57 @@
+package t4g
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ScrapeStatus is the outcome of scraping events from Tickets For Good
+type ScrapeStatus struct {
+	LastAttemptAt time.Time // Time of the last scrape. Zero if there has not been a scrape.
+	LastSuccessAt time.Time // Time of the last successful scrape. Zero if no scrape has succeeded.
+	LastError     error     // Error of the last scrape. Nil if it got every event page.
+}
+
+// LastSucceeded returns whether the last scrape succeeded.
+// Scrapes that got some, but not all, event pages are considered successful.
+func (s ScrapeStatus) LastSucceeded() bool {
+	return !s.LastAttemptAt.IsZero() && s.LastSuccessAt.Equal(s.LastAttemptAt)
+}
+
+// Ready returns whether events can be scraped from Tickets For Good at a time, based on the last scrape.
+// If the last scrape failed, it is still considered ready if a scrape succeeded within the grace period.
+// Before the first scrape it is considered ready, as nothing is known to be wrong.
+func (s ScrapeStatus) Ready(now time.Time, gracePeriod time.Duration) bool {
+	if s.LastAttemptAt.IsZero() || s.LastSucceeded() {
+		return true
+	}
+
+	return !s.LastSuccessAt.IsZero() && now.Sub(s.LastSuccessAt) <= gracePeriod
+}
+
+// LastScrape returns the status of the last scrape of events from Tickets For Good by the client
+func (c *Client) LastScrape() ScrapeStatus {
+	c.scrapeStatusMutex.RLock()
+	defer c.scrapeStatusMutex.RUnlock()
+	return c.scrapeStatus
+}
+
+// recordScrape records the outcome of a scrape. Scrapes that
+// were cancelled are ignored, as they say nothing about upstream.
+func (c *Client) recordScrape(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	c.scrapeStatusMutex.Lock()
+	defer c.scrapeStatusMutex.Unlock()
+
+	c.scrapeStatus.LastAttemptAt = c.now()
+	c.scrapeStatus.LastError = err
+
+	var partialErr *PartialError
+	if err == nil || errors.As(err, &partialErr) {
+		c.scrapeStatus.LastSuccessAt = c.scrapeStatus.LastAttemptAt
+	}
+}
@@ -0,0 +1,61 @@
+package t4g_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeStatusReady(t *testing.T) {
+	now := time.Now()
+	scrapeErr := errors.New("scrape failed")
+
+	tests := []struct {
+		name        string
+		status      t4g.ScrapeStatus
+		gracePeriod time.Duration
+		ready       bool
+	}{
+		{
+			name:  "no scrape",
+			ready: true,
+		},
+		{
+			name:   "last scrape succeeded",
+			status: t4g.ScrapeStatus{LastAttemptAt: now, LastSuccessAt: now},
+			ready:  true,
+		},
+		{
+			name:   "last scrape failed",
+			status: t4g.ScrapeStatus{LastAttemptAt: now, LastSuccessAt: now.Add(-time.Minute), LastError: scrapeErr},
+			ready:  false,
+		},
+		{
+			name:        "last scrape failed within grace period",
+			status:      t4g.ScrapeStatus{LastAttemptAt: now, LastSuccessAt: now.Add(-time.Minute), LastError: scrapeErr},
+			gracePeriod: 5 * time.Minute,
+			ready:       true,
+		},
+		{
+			name:        "last scrape failed after grace period",
+			status:      t4g.ScrapeStatus{LastAttemptAt: now, LastSuccessAt: now.Add(-time.Hour), LastError: scrapeErr},
+			gracePeriod: 5 * time.Minute,
+			ready:       false,
+		},
+		{
+			name:        "no scrape succeeded",
+			status:      t4g.ScrapeStatus{LastAttemptAt: now, LastError: scrapeErr},
+			gracePeriod: 5 * time.Minute,
+			ready:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.ready, test.status.Ready(now, test.gracePeriod))
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package t4g
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/utils"
+	"github.com/gorilla/feeds"
+)
+
+// ErrFeedNotFound is returned by a feed store when a feed does not exist in the store
+var ErrFeedNotFound = errors.New("feed not found")
+
+// FeedStore is a store of feed snapshots, allowing feed item history to survive restarts
+type FeedStore interface {
+	// LoadFeed loads the snapshot of a feed. If the feed does not exist ErrFeedNotFound is returned.
+	LoadFeed(key string) (*FeedSnapshot, error)
+	// SaveFeed saves the snapshot of a feed, replacing any existing snapshot.
+	SaveFeed(key string, snapshot *FeedSnapshot) error
+	// DeleteFeed deletes the snapshot of a feed. Deleting a feed that does not exist is not an error.
+	DeleteFeed(key string) error
+}
+
+// FeedSnapshot is a snapshot of the state of a feed
+type FeedSnapshot struct {
+	Updated   time.Time     `json:"updated"`
+	Refreshed time.Time     `json:"refreshed"`
+	Items     []*feeds.Item `json:"items"`
+	Events    []Event       `json:"events"` // Events of the feed items
+}
+
+// DirFeedStore is a feed store that stores feed snapshots as json files in a directory
+type DirFeedStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewDirFeedStore creates a feed store using a directory, creating the directory if it does not exist
+func NewDirFeedStore(dir string) (*DirFeedStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feed store directory: %w", err)
+	}
+
+	return &DirFeedStore{dir: dir}, nil
+}
+
+func (s *DirFeedStore) LoadFeed(key string) (*FeedSnapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshotBytes, err := os.ReadFile(s.snapshotPath(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, err
+	}
+
+	var snapshot FeedSnapshot
+	err = json.Unmarshal(snapshotBytes, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode feed snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+func (s *DirFeedStore) SaveFeed(key string, snapshot *FeedSnapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshotBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode feed snapshot: %w", err)
+	}
+
+	// Written atomically, so a snapshot is never partially written
+	return utils.WriteFile(s.snapshotPath(key), snapshotBytes, 0o644)
+}
+
+func (s *DirFeedStore) DeleteFeed(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := os.Remove(s.snapshotPath(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// snapshotPath returns the path of the snapshot file of a feed.
+// Keys are hashed as they can contain characters that are not valid in file names.
+func (s *DirFeedStore) snapshotPath(key string) string {
+	keyHash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(keyHash[:])+".json")
+}
+
+// FileFeedStore is a feed store that stores the snapshots of all feeds in a single json state file
+type FileFeedStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileFeedStore creates a feed store using a state file. The file is created when a feed is first saved.
+func NewFileFeedStore(path string) *FileFeedStore {
+	return &FileFeedStore{path: path}
+}
+
+func (s *FileFeedStore) LoadFeed(key string) (*FeedSnapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshots, err := s.loadSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, ok := snapshots[key]
+	if !ok {
+		return nil, ErrFeedNotFound
+	}
+
+	return snapshot, nil
+}
+
+func (s *FileFeedStore) SaveFeed(key string, snapshot *FeedSnapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshots, err := s.loadSnapshots()
+	if err != nil {
+		return err
+	}
+	snapshots[key] = snapshot
+
+	return s.saveSnapshots(snapshots)
+}
+
+func (s *FileFeedStore) DeleteFeed(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshots, err := s.loadSnapshots()
+	if err != nil {
+		return err
+	}
+	if _, ok := snapshots[key]; !ok {
+		return nil
+	}
+	delete(snapshots, key)
+
+	return s.saveSnapshots(snapshots)
+}
+
+// loadSnapshots loads the snapshots in the state file, as a map of feed key to snapshot.
+// If the state file does not exist, there are no snapshots.
+func (s *FileFeedStore) loadSnapshots() (map[string]*FeedSnapshot, error) {
+	snapshotsBytes, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]*FeedSnapshot{}, nil
+		}
+		return nil, err
+	}
+
+	var snapshots map[string]*FeedSnapshot
+	err = json.Unmarshal(snapshotsBytes, &snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode feed snapshots: %w", err)
+	}
+	if snapshots == nil {
+		snapshots = map[string]*FeedSnapshot{}
+	}
+
+	return snapshots, nil
+}
+
+// saveSnapshots replaces the snapshots in the state file, as a map of feed key to snapshot
+func (s *FileFeedStore) saveSnapshots(snapshots map[string]*FeedSnapshot) error {
+	snapshotsBytes, err := json.Marshal(snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to encode feed snapshots: %w", err)
+	}
+
+	return utils.WriteFile(s.path, snapshotsBytes, 0o644)
+}
@@ -0,0 +1,169 @@
+package t4g_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/t4g/t4gtest"
+	"github.com/gorilla/feeds"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirFeedStore(t *testing.T) {
+	storeDir := t.TempDir()
+	store, err := t4g.NewDirFeedStore(storeDir)
+	require.NoError(t, err)
+
+	_, err = store.LoadFeed("london")
+	require.ErrorIs(t, err, t4g.ErrFeedNotFound)
+
+	created := time.Date(2024, 1, 20, 19, 30, 0, 0, time.UTC)
+	snapshot := &t4g.FeedSnapshot{
+		Updated: created.Add(time.Hour),
+		Items: []*feeds.Item{
+			{
+				Id:      "123",
+				Title:   "Hamlet",
+				Link:    &feeds.Link{Href: "https://nhs.ticketsforgood.co.uk/events/123"},
+				Created: created,
+			},
+		},
+	}
+
+	err = store.SaveFeed("london", snapshot)
+	require.NoError(t, err)
+
+	loadedSnapshot, err := store.LoadFeed("london")
+	require.NoError(t, err)
+	require.True(t, snapshot.Updated.Equal(loadedSnapshot.Updated))
+	require.Len(t, loadedSnapshot.Items, 1)
+	require.Equal(t, "123", loadedSnapshot.Items[0].Id)
+	require.True(t, created.Equal(loadedSnapshot.Items[0].Created))
+
+	_, err = store.LoadFeed("leeds")
+	require.ErrorIs(t, err, t4g.ErrFeedNotFound)
+
+	// Deleting feeds that do not exist does nothing
+	err = store.DeleteFeed("leeds")
+	require.NoError(t, err)
+
+	// Only the snapshot file should be left in the directory, readable by everyone
+	snapshotPaths, err := filepath.Glob(filepath.Join(storeDir, "*"))
+	require.NoError(t, err)
+	require.Len(t, snapshotPaths, 1)
+	fileInfo, err := os.Stat(snapshotPaths[0])
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), fileInfo.Mode().Perm())
+}
+
+func TestDirFeedStoreKeepsFeedsBetweenClients(t *testing.T) {
+	server := t4gtest.NewServer(t)
+	store, err := t4g.NewDirFeedStore(t.TempDir())
+	require.NoError(t, err)
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := server.Client(t, t4g.WithFeedStore(store), t4g.WithClock(func() time.Time { return created }))
+
+	input := t4g.FeedInput{Location: lo.ToPtr("store")}
+	_, err = client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+	eventRequests := server.EventRequests()
+
+	// A new client on the same store, such as after a restart, should continue the feed
+	restarted := created.Add(time.Hour)
+	client = server.Client(t, t4g.WithFeedStore(store), t4g.WithClock(func() time.Time { return restarted }))
+
+	feed, err := client.FetchFeed(context.Background(), input, nil)
+	require.NoError(t, err)
+	require.Equal(t, restarted, feed.RefreshedAt())
+	require.Len(t, feed.Events(), t4gtest.Page1Events)
+
+	// The events are already known, so items keep the time they were created and are not fetched again
+	require.True(t, created.Equal(feed.UpdatedAt()))
+	for _, item := range feed.Snapshot().Items {
+		require.True(t, created.Equal(item.Created))
+	}
+	require.Equal(t, eventRequests, server.EventRequests())
+}
+
+func TestFileFeedStore(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	store := t4g.NewFileFeedStore(statePath)
+
+	_, err := store.LoadFeed("london")
+	require.ErrorIs(t, err, t4g.ErrFeedNotFound)
+
+	created := time.Date(2024, 1, 20, 19, 30, 0, 0, time.UTC)
+	londonSnapshot := &t4g.FeedSnapshot{
+		Updated: created.Add(time.Hour),
+		Items:   []*feeds.Item{{Id: "123", Title: "Hamlet", Created: created}},
+	}
+	leedsSnapshot := &t4g.FeedSnapshot{
+		Updated: created,
+		Items:   []*feeds.Item{{Id: "456", Title: "Macbeth", Created: created}},
+	}
+
+	err = store.SaveFeed("london", londonSnapshot)
+	require.NoError(t, err)
+	err = store.SaveFeed("leeds", leedsSnapshot)
+	require.NoError(t, err)
+
+	// Snapshots of every feed should be kept in the state file
+	store = t4g.NewFileFeedStore(statePath)
+
+	loadedSnapshot, err := store.LoadFeed("london")
+	require.NoError(t, err)
+	require.Equal(t, "123", loadedSnapshot.Items[0].Id)
+	require.True(t, created.Equal(loadedSnapshot.Items[0].Created))
+
+	loadedSnapshot, err = store.LoadFeed("leeds")
+	require.NoError(t, err)
+	require.Equal(t, "456", loadedSnapshot.Items[0].Id)
+
+	// Deleted feeds should be removed from the state file, keeping the other feeds
+	err = store.DeleteFeed("leeds")
+	require.NoError(t, err)
+	_, err = store.LoadFeed("leeds")
+	require.ErrorIs(t, err, t4g.ErrFeedNotFound)
+	_, err = t4g.NewFileFeedStore(statePath).LoadFeed("london")
+	require.NoError(t, err)
+
+	// Corrupt state files should not be overwritten
+	err = os.WriteFile(statePath, []byte("not json"), 0o600)
+	require.NoError(t, err)
+
+	err = store.SaveFeed("london", londonSnapshot)
+	require.ErrorContains(t, err, "failed to decode feed snapshots")
+}
+
+func TestDirFeedStoreDeletesEvictedFeeds(t *testing.T) {
+	storeDir := t.TempDir()
+	store, err := t4g.NewDirFeedStore(storeDir)
+	require.NoError(t, err)
+	client := t4gtest.NewServer(t).Client(t, t4g.WithFeedStore(store), t4g.WithMaxCachedFeeds(1))
+
+	evictedInput := t4g.FeedInput{Location: lo.ToPtr("store-evicted")}
+	_, err = client.FetchFeed(context.Background(), evictedInput, nil)
+	require.NoError(t, err)
+	_, err = store.LoadFeed(client.FeedKey(evictedInput))
+	require.NoError(t, err)
+
+	// Feeds evicted from the cache should be deleted from the store, so it does not grow with every feed requested
+	keptInput := t4g.FeedInput{Location: lo.ToPtr("store-kept")}
+	_, err = client.FetchFeed(context.Background(), keptInput, nil)
+	require.NoError(t, err)
+
+	_, err = store.LoadFeed(client.FeedKey(evictedInput))
+	require.ErrorIs(t, err, t4g.ErrFeedNotFound)
+	_, err = store.LoadFeed(client.FeedKey(keptInput))
+	require.NoError(t, err)
+
+	snapshotPaths, err := filepath.Glob(filepath.Join(storeDir, "*"))
+	require.NoError(t, err)
+	require.Len(t, snapshotPaths, 1)
+}
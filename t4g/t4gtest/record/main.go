@@ -0,0 +1,96 @@
+// Command record records the pages of Tickets For Good served by the t4gtest server.
+//
+// It fetches the first two listing pages of events of a location, a listing page past the last page of events,
+// and the event page of the first event, scrubs tokens from them, and writes them to the testdata directory:
+//
+//	go run ./t4g/t4gtest/record -location london
+//
+// Dates of events are kept, as they are parsed by the client. The constants of t4gtest describe the events
+// of the fixtures, so must be updated after recording.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/ahobsonsayers/t4g-feed/utils"
+	"github.com/samber/lo"
+)
+
+// emptyPage is a listing page far past the last page of events, so it has no events
+const emptyPage = 1000
+
+// tokenRegexes match tokens in pages that change between requests or identify a session
+var tokenRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(<meta name="csrf-token" content=")[^"]*`),
+	regexp.MustCompile(`(name="authenticity_token" value=")[^"]*`),
+	regexp.MustCompile(`(nonce=")[^"]*`),
+}
+
+func main() {
+	location := flag.String("location", "london", "Location of the events to record")
+	outputDir := flag.String("output", filepath.Join("t4g", "t4gtest", "testdata"), "Directory to write pages to")
+	flag.Parse()
+
+	err := record(context.Background(), *location, *outputDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func record(ctx context.Context, location, outputDir string) error {
+	client, err := t4g.NewClient()
+	if err != nil {
+		return err
+	}
+	upstream := t4g.DefaultUpstreamClient()
+
+	pages := map[string]int{
+		"events_page_1.html": 1,
+		"events_page_2.html": 2,
+		"events_empty.html":  emptyPage,
+	}
+	for fileName, page := range pages {
+		pageUrl := client.EventsUrl(t4g.EventsInput{Location: &location, Page: lo.ToPtr(page)})
+		err := recordPage(ctx, upstream, pageUrl, filepath.Join(outputDir, fileName))
+		if err != nil {
+			return err
+		}
+	}
+
+	events, err := client.Events(ctx, nil, &location, nil, lo.ToPtr(1))
+	if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+	if len(events) == 0 {
+		return errors.New("no events found")
+	}
+
+	return recordPage(ctx, upstream, events[0].Link, filepath.Join(outputDir, "event.html"))
+}
+
+// recordPage gets a page, scrubs tokens from it, and writes it to a file
+func recordPage(ctx context.Context, upstream *t4g.UpstreamClient, pageUrl, path string) error {
+	page, err := upstream.GetPage(ctx, pageUrl)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", pageUrl, err)
+	}
+
+	for _, tokenRegex := range tokenRegexes {
+		page = tokenRegex.ReplaceAllString(page, "${1}scrubbed")
+	}
+
+	err = utils.WriteFile(path, []byte(page), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	log.Printf("Recorded %s to %s", pageUrl, path)
+	return nil
+}
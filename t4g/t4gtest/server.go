@@ -0,0 +1,238 @@
+// Package t4gtest provides a fake Tickets For Good site for testing without network access
+package t4gtest
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+)
+
+// testdata holds the pages served by the server. These are hand-written in the structure of the listing pages
+// of nhs.ticketsforgood.co.uk, as pages have not yet been recorded from the live site, and the event page has
+// not been checked against a real event page. Use the record command to replace them with recorded pages,
+// noting the site and date of the recording here.
+//
+//go:embed testdata
+var testdata embed.FS
+
+// Events of the listing page fixtures, newest first. Page 2 repeats the last event
+// of page 1, and also has a card without a link and a card with missing fields.
+// Page 3 onwards are empty.
+const (
+	NumPages         = 2     // Number of pages with events
+	Page1Events      = 12    // Number of events on page 1
+	Page2Events      = 9     // Number of valid events on page 2, excluding the repeated event
+	FirstEventId     = 40212 // Id of the first event on page 1
+	MalformedEventId = 40192 // Id of the event with missing fields on page 2. This is the last event.
+)
+
+// MirrorPortal is the name of a second portal served by the server, under the /mirror path
+const MirrorPortal = "mirror"
+
+// Server is a fake Tickets For Good site serving the listing and event pages of testdata
+type Server struct {
+	*httptest.Server
+
+	mutex            sync.Mutex
+	status           int                      // Status to respond to every listing page with. 0 if unset.
+	pageStatus       map[int]int              // Page number -> status to respond with instead of the page
+	pageContent      map[int]string           // Page number -> content to respond with instead of the fixture
+	eventStatus      int                      // Status to respond to every event page with. 0 if unset.
+	blockedLocations map[string]chan struct{} // Location -> channel closed when its pages are unblocked
+	requests         map[string]int           // Location and page, or event -> number of requests
+}
+
+// NewServer starts a fake Tickets For Good site until the test finishes.
+// Use Client to create clients that fetch events from it.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	server := &Server{
+		pageStatus:       map[int]int{},
+		pageContent:      map[int]string{},
+		blockedLocations: map[string]chan struct{}{},
+		requests:         map[string]int{},
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Client creates a client that fetches events from the server, with the default portal pointed at the
+// server and MirrorPortal also allowed. Upstream requests are not retried, so errors are returned straight
+// away, and only one page of events is fetched when updating feeds. Options can override these.
+func (s *Server) Client(t testing.TB, opts ...t4g.Option) *t4g.Client {
+	t.Helper()
+
+	upstream := t4g.DefaultUpstreamClient()
+	upstream.MaxAttempts = 1
+
+	portals := map[string]string{
+		t4g.DefaultPortalName: s.URL,
+		MirrorPortal:          s.URL + "/" + MirrorPortal,
+	}
+
+	defaultOpts := []t4g.Option{
+		t4g.WithUpstreamClient(upstream),
+		t4g.WithPortals(portals, t4g.DefaultPortalName),
+		t4g.WithEventPages(1),
+	}
+
+	client, err := t4g.NewClient(append(defaultOpts, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+
+	return client
+}
+
+// SetStatus makes the server respond to requests of every listing page with an error status.
+// A status of 0 makes the server respond with the pages again.
+func (s *Server) SetStatus(status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status = status
+}
+
+// SetPageStatus makes the server respond to requests of a listing page with an error status.
+// A status of 0 makes the server respond with the page again.
+func (s *Server) SetPageStatus(page, status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if status == 0 {
+		delete(s.pageStatus, page)
+		return
+	}
+	s.pageStatus[page] = status
+}
+
+// SetPageContent makes the server respond to requests of a listing page with content instead of its fixture
+func (s *Server) SetPageContent(page int, content string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pageContent[page] = content
+}
+
+// SetEventStatus makes the server respond to requests of every event page with an error status.
+// A status of 0 makes the server respond with the event page again.
+func (s *Server) SetEventStatus(status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.eventStatus = status
+}
+
+// BlockLocation makes requests of listing pages of a location wait until the returned function is called
+func (s *Server) BlockLocation(location string) (unblock func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	blocked := make(chan struct{})
+	s.blockedLocations[location] = blocked
+
+	return sync.OnceFunc(func() { close(blocked) })
+}
+
+// PageRequests returns the number of requests of a listing page of a location
+func (s *Server) PageRequests(location string, page int) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[pageKey(location, page)]
+}
+
+// EventRequests returns the number of requests of event pages
+func (s *Server) EventRequests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests["event"]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// The mirror portal serves the same pages
+	path := strings.TrimPrefix(r.URL.Path, "/"+MirrorPortal)
+
+	switch {
+	case path == "/events":
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		location := r.URL.Query().Get("location")
+		s.waitUntilUnblocked(r, location)
+		s.serveEventsPage(w, location, page)
+
+	case strings.HasPrefix(path, "/events/"):
+		s.mutex.Lock()
+		s.requests["event"]++
+		status := s.eventStatus
+		s.mutex.Unlock()
+
+		if status != 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		serveFixture(w, "event.html")
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveEventsPage(w http.ResponseWriter, location string, page int) {
+	s.mutex.Lock()
+	s.requests[pageKey(location, page)]++
+	status, hasStatus := s.pageStatus[page]
+	if s.status != 0 {
+		status, hasStatus = s.status, true
+	}
+	content, hasContent := s.pageContent[page]
+	s.mutex.Unlock()
+
+	switch {
+	case hasStatus:
+		http.Error(w, http.StatusText(status), status)
+	case hasContent:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(content))
+	case page <= NumPages:
+		serveFixture(w, fmt.Sprintf("events_page_%d.html", page))
+	default:
+		serveFixture(w, "events_empty.html")
+	}
+}
+
+func (s *Server) waitUntilUnblocked(r *http.Request, location string) {
+	s.mutex.Lock()
+	blocked, isBlocked := s.blockedLocations[location]
+	s.mutex.Unlock()
+
+	if isBlocked {
+		select {
+		case <-blocked:
+		case <-r.Context().Done():
+		}
+	}
+}
+
+func serveFixture(w http.ResponseWriter, name string) {
+	content, err := testdata.ReadFile("testdata/" + name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(content)
+}
+
+func pageKey(location string, page int) string {
+	return fmt.Sprintf("%s page %d", location, page)
+}
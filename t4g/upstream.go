@@ -0,0 +1,153 @@
+package t4g
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/utils"
+)
+
+const DefaultUserAgent = "t4g-feed (+https://github.com/ahobsonsayers/t4g-feed)"
+
+// ErrResponseTooLarge is returned when an upstream response body is larger than the maximum size
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// UpstreamClient fetches pages from Tickets For Good.
+// Requests that fail with a network error, server error or rate limit are retried
+// with exponential backoff and jitter, honouring any Retry-After header.
+type UpstreamClient struct {
+	HTTPClient       *http.Client  // Client used to make requests. If nil, http.DefaultClient is used.
+	UserAgent        string        // User-Agent header of requests
+	RequestTimeout   time.Duration // Timeout of each request attempt. If zero, attempts have no timeout.
+	MaxAttempts      int           // Maximum number of attempts of a request. Requests are always attempted once.
+	InitialBackoff   time.Duration // Time to wait before the first retry, doubled after each retry
+	MaxBackoff       time.Duration // Maximum time to wait between retries, including when set by Retry-After
+	MaxResponseBytes int64         // Maximum size of a response body. If zero, the size is not limited.
+
+	metrics *metrics // Metrics of the client using the upstream client. If nil, no metrics are recorded.
+}
+
+// DefaultUpstreamClient returns an upstream client with the default configuration
+func DefaultUpstreamClient() *UpstreamClient {
+	return &UpstreamClient{
+		HTTPClient:       http.DefaultClient,
+		UserAgent:        DefaultUserAgent,
+		RequestTimeout:   15 * time.Second,
+		MaxAttempts:      3,
+		InitialBackoff:   500 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		MaxResponseBytes: 5 << 20, // 5MiB
+	}
+}
+
+// GetPage gets the content of a page, retrying failed requests
+func (c *UpstreamClient) GetPage(ctx context.Context, pageUrl string) (string, error) {
+	retryOptions := utils.RetryOptions{
+		MaxAttempts:    c.MaxAttempts,
+		InitialBackoff: c.InitialBackoff,
+		MaxBackoff:     c.MaxBackoff,
+	}
+
+	var page string
+	err := utils.Retry(ctx, retryOptions, func(ctx context.Context) (time.Duration, error) {
+		var retryAfter time.Duration
+		var err error
+		page, retryAfter, err = c.getPage(ctx, pageUrl)
+		return retryAfter, err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return page, nil
+}
+
+// getPage makes a single attempt to get the content of a page.
+// If upstream responds with a retry after header, its duration is returned.
+func (c *UpstreamClient) getPage(ctx context.Context, pageUrl string) (string, time.Duration, error) {
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageUrl, http.NoBody)
+	if err != nil {
+		return "", 0, utils.Permanent(err)
+	}
+	if c.UserAgent != "" {
+		request.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	start := time.Now()
+	response, err := httpClient.Do(request)
+	if err != nil {
+		c.metrics.observeUpstreamRequest("error", start)
+		return "", 0, err
+	}
+	defer response.Body.Close()
+
+	status := strconv.Itoa(response.StatusCode)
+
+	// Limit the size of bodies, including those of error responses.
+	// One more byte than the maximum is read to detect bodies that are too large.
+	if c.MaxResponseBytes > 0 {
+		response.Body = io.NopCloser(io.LimitReader(response.Body, c.MaxResponseBytes+1))
+	}
+
+	httpError := utils.HTTPResponseError(response)
+	if httpError != nil {
+		c.metrics.observeUpstreamRequest(status, start)
+
+		// Only retry server errors and rate limiting
+		if response.StatusCode < http.StatusInternalServerError && response.StatusCode != http.StatusTooManyRequests {
+			return "", 0, utils.Permanent(httpError)
+		}
+
+		return "", retryAfter(response.Header.Get("Retry-After")), httpError
+	}
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	c.metrics.observeUpstreamRequest(status, start)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if c.MaxResponseBytes > 0 && int64(len(bodyBytes)) > c.MaxResponseBytes {
+		return "", 0, utils.Permanent(fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.MaxResponseBytes))
+	}
+
+	c.metrics.observeUpstreamResponse(len(bodyBytes))
+
+	return string(bodyBytes), 0, nil
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a number of seconds
+// or a http date. Zero is returned if the value is not set or invalid.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	retryTime, err := http.ParseTime(value)
+	if err == nil {
+		return max(time.Until(retryTime), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,122 @@
+package t4g_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahobsonsayers/t4g-feed/t4g"
+	"github.com/stretchr/testify/require"
+)
+
+func testUpstreamClient() *t4g.UpstreamClient {
+	client := t4g.DefaultUpstreamClient()
+	client.InitialBackoff = time.Millisecond
+	client.MaxBackoff = 10 * time.Millisecond
+	return client
+}
+
+func TestUpstreamClientRetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, t4g.DefaultUserAgent, r.UserAgent())
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer upstream.Close()
+
+	page, err := testUpstreamClient().GetPage(context.Background(), upstream.URL)
+	require.NoError(t, err)
+	require.Equal(t, "page", page)
+	require.EqualValues(t, 3, requests.Load())
+}
+
+func TestUpstreamClientGivesUpAfterMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	client := testUpstreamClient()
+	client.MaxAttempts = 2
+
+	_, err := client.GetPage(context.Background(), upstream.URL)
+	require.Error(t, err)
+	require.EqualValues(t, 2, requests.Load())
+}
+
+func TestUpstreamClientDoesNotRetryClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	_, err := testUpstreamClient().GetPage(context.Background(), upstream.URL)
+	require.Error(t, err)
+	require.EqualValues(t, 1, requests.Load())
+}
+
+func TestUpstreamClientRetriesTimeouts(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer upstream.Close()
+
+	client := testUpstreamClient()
+	client.RequestTimeout = 50 * time.Millisecond
+
+	page, err := client.GetPage(context.Background(), upstream.URL)
+	require.NoError(t, err)
+	require.Equal(t, "page", page)
+	require.EqualValues(t, 2, requests.Load())
+}
+
+func TestUpstreamClientMaxResponseBytes(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer upstream.Close()
+
+	client := testUpstreamClient()
+	client.MaxResponseBytes = 100
+
+	_, err := client.GetPage(context.Background(), upstream.URL)
+	require.NoError(t, err)
+
+	client.MaxResponseBytes = 99
+
+	_, err = client.GetPage(context.Background(), upstream.URL)
+	require.ErrorIs(t, err, t4g.ErrResponseTooLarge)
+}
+
+func TestUpstreamClientMaxErrorResponseBytes(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, strings.Repeat("a", 1000), http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	client := testUpstreamClient()
+	client.MaxResponseBytes = 10
+
+	// Only the start of the body of error responses should be read
+	_, err := client.GetPage(context.Background(), upstream.URL)
+	require.ErrorContains(t, err, "404 Not Found: "+strings.Repeat("a", 11))
+	require.NotContains(t, err.Error(), strings.Repeat("a", 12))
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes a file by writing a temporary file and renaming it,
+// so the file is never partially written if it is being read
+func WriteFile(path string, content []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(content)
+	if err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	// Temporary files are created only readable by their owner
+	err = os.Chmod(tempFile.Name(), perm)
+	if err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	err = os.Rename(tempFile.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryOptions configure how an operation is retried
+type RetryOptions struct {
+	MaxAttempts    int           // Maximum number of attempts. Operations are always attempted once.
+	InitialBackoff time.Duration // Time to wait before the first retry, doubled after each retry
+	MaxBackoff     time.Duration // Maximum time to wait between retries, including when set by the operation
+}
+
+// Retry attempts an operation until it succeeds, returns a permanent error, runs out of attempts, or the context
+// is cancelled. Retries wait with exponential backoff and jitter, unless the failed attempt returned a positive
+// time to wait before retrying, such as from a Retry-After header. The errors of every attempt are joined.
+func Retry(
+	ctx context.Context, options RetryOptions, operation func(ctx context.Context) (retryAfter time.Duration, err error),
+) error {
+	var errs error
+	backoff := options.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := operation(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = errors.Join(errs, fmt.Errorf("attempt %d: %w", attempt, err))
+
+		var permanentErr *PermanentError
+		if errors.As(err, &permanentErr) || ctx.Err() != nil || attempt >= options.MaxAttempts {
+			return errs
+		}
+
+		// Wait before retrying, using retry after if the operation set it
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if options.MaxBackoff > 0 {
+			wait = min(wait, options.MaxBackoff)
+			backoff = min(backoff*2, options.MaxBackoff)
+		} else {
+			backoff *= 2
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(errs, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// PermanentError is an error that should not be retried
+type PermanentError struct{ Err error }
+
+// Permanent marks an error as permanent, so it is not retried
+func Permanent(err error) error { return &PermanentError{Err: err} }
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }